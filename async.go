@@ -0,0 +1,151 @@
+package zaptextencoder
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DropPolicy decides what an AsyncWriter does with a write when its queue
+// is full.
+type DropPolicy int
+
+const (
+	// BlockOnFull waits for room in the queue, which is the default.
+	BlockOnFull DropPolicy = iota
+	// DropOnFull drops the write, see AsyncWriter.DroppedCount.
+	DropOnFull
+)
+
+// ErrAsyncWriterClosed is returned by writes to a closed AsyncWriter.
+var ErrAsyncWriterClosed = errors.New("async writer closed")
+
+// AsyncWriter is a zapcore.WriteSyncer queueing writes for a goroutine of
+// its own to write, so that logging doesn't wait on a slow writer. Errors
+// of the underlying writer are returned by the next Sync or Close.
+type AsyncWriter struct {
+	dropped uint64 // first for 64-bit alignment of atomic operations
+
+	ws     zapcore.WriteSyncer
+	policy DropPolicy
+	queue  chan asyncWrite
+	done   chan struct{}
+
+	// mu guards closed, and is held for reading while writing to queue so
+	// that Close doesn't close it under a write.
+	mu     sync.RWMutex
+	closed bool
+
+	errMu sync.Mutex
+	err   error
+}
+
+// asyncWrite is a write to the underlying writer, or a request to sync it
+// if synced is set.
+type asyncWrite struct {
+	p      []byte
+	synced chan error
+}
+
+// AsyncWriteSyncer returns an AsyncWriter writing to ws, whose queue holds
+// up to queueSize writes. A queueSize below 1 is taken as 1. The AsyncWriter
+// must be closed to stop its goroutine.
+func AsyncWriteSyncer(ws zapcore.WriteSyncer, queueSize int, policy DropPolicy) *AsyncWriter {
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	w := &AsyncWriter{
+		ws:     ws,
+		policy: policy,
+		queue:  make(chan asyncWrite, queueSize),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+	for aw := range w.queue {
+		if aw.synced != nil {
+			err := w.ws.Sync()
+			w.errMu.Lock()
+			if err == nil {
+				err = w.err
+			}
+			w.err = nil
+			w.errMu.Unlock()
+			aw.synced <- err
+			continue
+		}
+		if _, err := w.ws.Write(aw.p); err != nil {
+			w.errMu.Lock()
+			if w.err == nil {
+				w.err = err
+			}
+			w.errMu.Unlock()
+		}
+	}
+}
+
+// Write queues a copy of p, as zap reuses the buffers it writes.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return 0, ErrAsyncWriterClosed
+	}
+	aw := asyncWrite{p: append([]byte(nil), p...)}
+	if w.policy == DropOnFull {
+		select {
+		case w.queue <- aw:
+		default:
+			atomic.AddUint64(&w.dropped, 1)
+		}
+		return len(p), nil
+	}
+	w.queue <- aw
+	return len(p), nil
+}
+
+// Sync waits for the writes queued so far to be written, then syncs the
+// underlying writer. It returns the first error of either since the last
+// Sync.
+func (w *AsyncWriter) Sync() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return ErrAsyncWriterClosed
+	}
+	synced := make(chan error, 1)
+	w.queue <- asyncWrite{synced: synced}
+	return <-synced
+}
+
+// Close writes the queued writes, syncs the underlying writer and stops
+// the goroutine of w. Later writes return ErrAsyncWriterClosed.
+func (w *AsyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrAsyncWriterClosed
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	<-w.done
+	err := w.ws.Sync()
+	if w.err != nil {
+		err = w.err
+	}
+	return err
+}
+
+// DroppedCount returns the number of writes dropped because the queue was
+// full, with DropOnFull.
+func (w *AsyncWriter) DroppedCount() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
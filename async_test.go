@@ -0,0 +1,117 @@
+package zaptextencoder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// blockingWriter records writes once released.
+type blockingWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	syncErr error
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{release: make(chan struct{})}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) Sync() error { return w.syncErr }
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriterOrder(t *testing.T) {
+	out := newBlockingWriter()
+	close(out.release)
+	w := AsyncWriteSyncer(out, 4, BlockOnFull)
+	logger := zap.New(zapcore.NewCore(NewTextEncoder(_optionsEncoderConfig), w, zap.DebugLevel))
+
+	var expected strings.Builder
+	for i := 0; i < 100; i++ {
+		logger.Info("lob law", zap.Int("i", i))
+		fmt.Fprintf(&expected, "info   lob law  i=%d\n", i)
+	}
+	require.NoError(t, w.Sync(), "Unexpected error syncing.")
+	assert.Equal(t, expected.String(), out.String(), "Expected the writes in order.")
+	assert.Zero(t, w.DroppedCount(), "Expected no dropped writes when blocking.")
+	assert.NoError(t, w.Close(), "Unexpected error closing.")
+}
+
+func TestAsyncWriterDropOnFull(t *testing.T) {
+	out := newBlockingWriter()
+	w := AsyncWriteSyncer(out, 2, DropOnFull)
+
+	// The first write is taken off the queue by the goroutine, which then
+	// blocks, and the next two fill the queue. Wait for the first to leave
+	// the queue so that the count is deterministic.
+	_, err := w.Write([]byte("0\n"))
+	require.NoError(t, err, "Unexpected error writing.")
+	for len(w.queue) > 0 {
+		runtime.Gosched()
+	}
+	for i := 1; i <= 5; i++ {
+		n, err := w.Write([]byte(fmt.Sprintf("%d\n", i)))
+		require.NoError(t, err, "Unexpected error writing.")
+		assert.Equal(t, 2, n, "Expected dropped writes to be reported as written.")
+	}
+	assert.Equal(t, uint64(3), w.DroppedCount(), "Unexpected dropped count.")
+
+	close(out.release)
+	assert.NoError(t, w.Close(), "Unexpected error closing.")
+	assert.Equal(t, "0\n1\n2\n", out.String(), "Expected the queued writes to be flushed.")
+}
+
+func TestAsyncWriterClose(t *testing.T) {
+	out := newBlockingWriter()
+	w := AsyncWriteSyncer(out, 8, BlockOnFull)
+	for i := 0; i < 5; i++ {
+		_, err := w.Write([]byte(fmt.Sprintf("%d\n", i)))
+		require.NoError(t, err, "Unexpected error writing.")
+	}
+	close(out.release)
+	assert.NoError(t, w.Close(), "Unexpected error closing.")
+	assert.Equal(t, "0\n1\n2\n3\n4\n", out.String(), "Expected Close to flush the queue.")
+
+	_, err := w.Write([]byte("late\n"))
+	assert.Equal(t, ErrAsyncWriterClosed, err, "Expected writes after Close to fail.")
+	assert.Equal(t, ErrAsyncWriterClosed, w.Sync(), "Expected Sync after Close to fail.")
+	assert.Equal(t, ErrAsyncWriterClosed, w.Close(), "Expected a second Close to fail.")
+}
+
+func TestAsyncWriterErrors(t *testing.T) {
+	w := AsyncWriteSyncer(zapcore.AddSync(failingWriter{}), 1, BlockOnFull)
+	_, err := w.Write([]byte("lost\n"))
+	assert.NoError(t, err, "Expected the write error to be deferred.")
+	assert.EqualError(t, w.Sync(), "disk full", "Expected Sync to return the write error.")
+	assert.NoError(t, w.Sync(), "Expected the error to be returned once.")
+
+	out := newBlockingWriter()
+	close(out.release)
+	out.syncErr = errors.New("sync failed")
+	w2 := AsyncWriteSyncer(out, 1, BlockOnFull)
+	assert.EqualError(t, w2.Sync(), "sync failed", "Expected the error of the underlying Sync.")
+	assert.EqualError(t, w2.Close(), "sync failed", "Expected the error of the underlying Sync.")
+	assert.NoError(t, w.Close(), "Unexpected error closing.")
+}
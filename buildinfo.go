@@ -0,0 +1,19 @@
+//go:build go1.18
+// +build go1.18
+
+package zaptextencoder
+
+import "runtime/debug"
+
+func goVersion(info *debug.BuildInfo) string {
+	return info.GoVersion
+}
+
+func vcsRevision(info *debug.BuildInfo) string {
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			return s.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,20 @@
+//go:build !go1.18
+// +build !go1.18
+
+package zaptextencoder
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// goVersion falls back to the version of the running binary, as Go versions
+// before 1.18 don't record it in the build information.
+func goVersion(*debug.BuildInfo) string {
+	return runtime.Version()
+}
+
+// vcsRevision is unknown, as Go versions before 1.18 don't record it.
+func vcsRevision(*debug.BuildInfo) string {
+	return ""
+}
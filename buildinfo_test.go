@@ -0,0 +1,67 @@
+//go:build go1.18
+// +build go1.18
+
+package zaptextencoder
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestWithAutoBuildInfo(t *testing.T) {
+	tests := []struct {
+		desc     string
+		info     *debug.BuildInfo
+		expected string
+	}{
+		{
+			desc: "full",
+			info: &debug.BuildInfo{
+				GoVersion: "go1.18",
+				Main:      debug.Module{Path: "example.com/svc", Version: "v1.2.3"},
+				Settings: []debug.BuildSetting{
+					{Key: "vcs", Value: "git"},
+					{Key: "vcs.revision", Value: "abc123"},
+				},
+			},
+			expected: "error  go_version=\"go1.18\"  module=\"example.com/svc\"  module_version=\"v1.2.3\"  vcs_revision=\"abc123\"  lob law  k=1\n",
+		},
+		{
+			desc: "no revision",
+			info: &debug.BuildInfo{
+				GoVersion: "go1.18",
+				Main:      debug.Module{Path: "example.com/svc", Version: "(devel)"},
+			},
+			expected: "error  go_version=\"go1.18\"  module=\"example.com/svc\"  module_version=\"(devel)\"  lob law  k=1\n",
+		},
+		{
+			desc:     "unavailable",
+			expected: "error  lob law  k=1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			orig := readBuildInfo
+			readBuildInfo = func() (*debug.BuildInfo, bool) { return tt.info, tt.info != nil }
+			defer func() { readBuildInfo = orig }()
+
+			assertEncodedEntry(t, tt.expected, []Option{WithAutoBuildInfo()}, zap.Int("k", 1))
+		})
+	}
+
+	t.Run("test binary", func(t *testing.T) {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			t.Skip("Test binary built without module support.")
+		}
+		buf, err := NewTextEncoder(_optionsEncoderConfig, WithAutoBuildInfo()).EncodeEntry(_optionsEntry, nil)
+		if assert.NoError(t, err, "Unexpected text encoding error.") {
+			assert.Contains(t, buf.String(), "go_version=\""+info.GoVersion+"\"", "Expected the Go version.")
+		}
+		buf.Free()
+	})
+}
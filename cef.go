@@ -0,0 +1,174 @@
+package zaptextencoder
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// DeviceInfo identifies the product writing CEF entries, see NewCEFEncoder.
+type DeviceInfo struct {
+	Vendor  string
+	Product string
+	Version string
+}
+
+// cefEncoder writes entries in the Common Event Format, see NewCEFEncoder.
+// ext holds the extension of the fields added so far.
+type cefEncoder struct {
+	cfg       *zapcore.EncoderConfig
+	device    DeviceInfo
+	ext       []byte
+	namespace string
+}
+
+// NewCEFEncoder creates an encoder writing entries in ArcSight's Common
+// Event Format, for products described by device, such as
+//
+//	CEF:0|Acme|api|1.0|db|lob law|3|answer=42 user=bob
+//
+// The signature ID is the name of the logger, or the level of entries
+// without one, the name is the message, and the severity grows from 1 for
+// debug entries through 3 for info, 5 for warn and 7 for error to 8, 9 and
+// 10 for dpanic, panic and fatal. Fields make up the extension, with '.'
+// between namespaces and keys; arrays, objects and reflected values are
+// written as JSON. '|' and '\' are escaped as "\|" and "\\" throughout, and
+// '=' and line breaks as "\=" and "\n" in the extension, where CEF leaves
+// '|' alone but escaping it keeps lines safe for splitting on bare pipes.
+func NewCEFEncoder(cfg zapcore.EncoderConfig, device DeviceInfo) zapcore.Encoder {
+	return &cefEncoder{cfg: &cfg, device: device}
+}
+
+// cefSeverities maps zap's levels, from DebugLevel, to CEF severities.
+var cefSeverities = [...]int{1, 3, 5, 7, 8, 9, 10}
+
+func cefSeverity(l zapcore.Level) int {
+	if l < zapcore.DebugLevel {
+		return cefSeverities[0]
+	}
+	if l > zapcore.FatalLevel {
+		return cefSeverities[len(cefSeverities)-1]
+	}
+	return cefSeverities[l-zapcore.DebugLevel]
+}
+
+var (
+	_cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", " ", "\r", " ", "\n", " ")
+	_cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "=", `\=`, "\r\n", `\n`, "\r", `\n`, "\n", `\n`)
+)
+
+// add writes key=val to the extension.
+func (enc *cefEncoder) add(key, val string) {
+	if len(enc.ext) > 0 {
+		enc.ext = append(enc.ext, ' ')
+	}
+	enc.ext = append(enc.ext, _cefExtensionEscaper.Replace(enc.namespace+key)...)
+	enc.ext = append(enc.ext, '=')
+	enc.ext = append(enc.ext, _cefExtensionEscaper.Replace(val)...)
+}
+
+// addJSON writes the JSON encoding of the field f to the extension.
+func (enc *cefEncoder) addJSON(key string, f zapcore.Field) error {
+	json := zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		EncodeTime:     enc.cfg.EncodeTime,
+		EncodeDuration: enc.cfg.EncodeDuration,
+	})
+	buf, err := json.EncodeEntry(zapcore.Entry{}, []zapcore.Field{f})
+	if err != nil {
+		return err
+	}
+	// Trim {"": and }\n.
+	enc.add(key, string(buf.Bytes()[4:buf.Len()-2]))
+	buf.Free()
+	return nil
+}
+
+func (enc *cefEncoder) AddArray(key string, v zapcore.ArrayMarshaler) error {
+	return enc.addJSON(key, zap.Array("", v))
+}
+
+func (enc *cefEncoder) AddObject(key string, v zapcore.ObjectMarshaler) error {
+	return enc.addJSON(key, zap.Object("", v))
+}
+
+func (enc *cefEncoder) AddReflected(key string, v interface{}) error {
+	return enc.addJSON(key, zap.Reflect("", v))
+}
+
+func (enc *cefEncoder) OpenNamespace(key string) {
+	enc.namespace += key + "."
+}
+
+func (enc *cefEncoder) AddBinary(key string, v []byte) {
+	enc.add(key, base64.StdEncoding.EncodeToString(v))
+}
+
+func (enc *cefEncoder) AddTime(key string, v time.Time) {
+	// CEF timestamps are milliseconds since the epoch.
+	enc.add(key, strconv.FormatInt(v.UnixNano()/int64(time.Millisecond), 10))
+}
+
+func (enc *cefEncoder) AddByteString(key string, v []byte)      { enc.add(key, string(v)) }
+func (enc *cefEncoder) AddBool(key string, v bool)              { enc.add(key, strconv.FormatBool(v)) }
+func (enc *cefEncoder) AddComplex128(key string, v complex128)  { enc.add(key, fmt.Sprint(v)) }
+func (enc *cefEncoder) AddComplex64(key string, v complex64)    { enc.add(key, fmt.Sprint(v)) }
+func (enc *cefEncoder) AddDuration(key string, v time.Duration) { enc.add(key, v.String()) }
+func (enc *cefEncoder) AddFloat64(key string, v float64) {
+	enc.add(key, strconv.FormatFloat(v, 'g', -1, 64))
+}
+func (enc *cefEncoder) AddFloat32(key string, v float32) {
+	enc.add(key, strconv.FormatFloat(float64(v), 'g', -1, 32))
+}
+func (enc *cefEncoder) AddInt(key string, v int)         { enc.AddInt64(key, int64(v)) }
+func (enc *cefEncoder) AddInt64(key string, v int64)     { enc.add(key, strconv.FormatInt(v, 10)) }
+func (enc *cefEncoder) AddInt32(key string, v int32)     { enc.AddInt64(key, int64(v)) }
+func (enc *cefEncoder) AddInt16(key string, v int16)     { enc.AddInt64(key, int64(v)) }
+func (enc *cefEncoder) AddInt8(key string, v int8)       { enc.AddInt64(key, int64(v)) }
+func (enc *cefEncoder) AddString(key, v string)          { enc.add(key, v) }
+func (enc *cefEncoder) AddUint(key string, v uint)       { enc.AddUint64(key, uint64(v)) }
+func (enc *cefEncoder) AddUint64(key string, v uint64)   { enc.add(key, strconv.FormatUint(v, 10)) }
+func (enc *cefEncoder) AddUint32(key string, v uint32)   { enc.AddUint64(key, uint64(v)) }
+func (enc *cefEncoder) AddUint16(key string, v uint16)   { enc.AddUint64(key, uint64(v)) }
+func (enc *cefEncoder) AddUint8(key string, v uint8)     { enc.AddUint64(key, uint64(v)) }
+func (enc *cefEncoder) AddUintptr(key string, v uintptr) { enc.AddUint64(key, uint64(v)) }
+
+func (enc *cefEncoder) Clone() zapcore.Encoder {
+	clone := *enc
+	clone.ext = enc.ext[:len(enc.ext):len(enc.ext)]
+	return &clone
+}
+
+func (enc *cefEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := enc.Clone().(*cefEncoder)
+	for i := range fields {
+		fields[i].AddTo(final)
+	}
+	sig := ent.LoggerName
+	if sig == "" {
+		sig = ent.Level.String()
+	}
+
+	buf := bufferPool.Get()
+	buf.AppendString("CEF:0")
+	for _, s := range []string{enc.device.Vendor, enc.device.Product, enc.device.Version, sig, ent.Message} {
+		buf.AppendByte('|')
+		buf.AppendString(_cefHeaderEscaper.Replace(s))
+	}
+	buf.AppendByte('|')
+	buf.AppendInt(int64(cefSeverity(ent.Level)))
+	buf.AppendByte('|')
+	buf.Write(final.ext)
+	if enc.cfg.LineEnding != "" {
+		buf.AppendString(enc.cfg.LineEnding)
+	} else {
+		buf.AppendString(zapcore.DefaultLineEnding)
+	}
+	bufferPool.handOff()
+	return buf, nil
+}
@@ -0,0 +1,81 @@
+package zaptextencoder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func encodeCEF(t *testing.T, enc zapcore.Encoder, ent zapcore.Entry, fields ...zapcore.Field) string {
+	buf, err := enc.EncodeEntry(ent, fields)
+	require.NoError(t, err, "Unexpected CEF encoding error.")
+	defer buf.Free()
+	return buf.String()
+}
+
+func TestCEFEncoder(t *testing.T) {
+	device := DeviceInfo{Vendor: "Acme", Product: "api", Version: "1.0"}
+	enc := NewCEFEncoder(zapcore.EncoderConfig{}, device)
+	enc.AddString("service", "api")
+	ns := enc.Clone()
+	ns.OpenNamespace("req")
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, LoggerName: "db", Message: "lob law"}
+
+	assert.Equal(t,
+		`CEF:0|Acme|api|1.0|db|lob law|3|service=api req.answer=42 req.ok=true req.at=1529426022123 req.took=1.5s req.ids=[1,2]`+"\n",
+		encodeCEF(t, ns, ent,
+			zap.Int("answer", 42),
+			zap.Bool("ok", true),
+			zap.Time("at", time.Unix(1529426022, 123456789)),
+			zap.Duration("took", 1500*time.Millisecond),
+			zap.Ints("ids", []int{1, 2}),
+		),
+		"Unexpected CEF entry.",
+	)
+	assert.Equal(t, "CEF:0|Acme|api|1.0|warn|lob law|5|service=api\n",
+		encodeCEF(t, enc.Clone(), zapcore.Entry{Level: zapcore.WarnLevel, Message: "lob law"}),
+		"Expected the level as the signature ID of entries without a logger name.",
+	)
+}
+
+func TestCEFEncoderSeverity(t *testing.T) {
+	enc := NewCEFEncoder(zapcore.EncoderConfig{}, DeviceInfo{})
+	for _, tt := range []struct {
+		level    zapcore.Level
+		severity string
+	}{
+		{zapcore.DebugLevel, "1"},
+		{zapcore.InfoLevel, "3"},
+		{zapcore.WarnLevel, "5"},
+		{zapcore.ErrorLevel, "7"},
+		{zapcore.DPanicLevel, "8"},
+		{zapcore.PanicLevel, "9"},
+		{zapcore.FatalLevel, "10"},
+	} {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			line := encodeCEF(t, enc, zapcore.Entry{Level: tt.level, LoggerName: "x", Message: "m"})
+			assert.Equal(t, "CEF:0||||x|m|"+tt.severity+"|\n", line, "Unexpected severity.")
+		})
+	}
+}
+
+func TestCEFEncoderEscaping(t *testing.T) {
+	device := DeviceInfo{Vendor: `Ac|me`, Product: `a\pi`, Version: "1.0"}
+	enc := NewCEFEncoder(zapcore.EncoderConfig{}, device)
+	ent := zapcore.Entry{Level: zapcore.ErrorLevel, LoggerName: "d|b", Message: "a|b\nc"}
+
+	assert.Equal(t,
+		`CEF:0|Ac\|me|a\\pi|1.0|d\|b|a\|b c|7|q=a\=b path=C:\\tmp msg=one\ntwo pipe=x\|y`+"\n",
+		encodeCEF(t, enc, ent,
+			zap.String("q", "a=b"),
+			zap.String("path", `C:\tmp`),
+			zap.String("msg", "one\ntwo"),
+			zap.String("pipe", "x|y"),
+		),
+		"Expected '|' and '\\' escaped everywhere, and '=' and line breaks in the extension.",
+	)
+}
@@ -0,0 +1,122 @@
+package zaptextencoder
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// The keys of the fields NewCLFEncoder writes, in the order of the line.
+const (
+	CLFRemoteAddrKey = "remote_addr"
+	CLFIdentKey      = "ident"
+	CLFRemoteUserKey = "remote_user"
+	CLFRequestKey    = "request"
+	CLFStatusKey     = "status"
+	CLFBytesSentKey  = "bytes_sent"
+)
+
+const clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// clfEncoder writes access log entries in the Common Log Format, see
+// NewCLFEncoder. namespaced tells whether fields go to a namespace, where
+// none of them are written.
+type clfEncoder struct {
+	*zapcore.MapObjectEncoder
+	cfg        *zapcore.EncoderConfig
+	namespaced bool
+}
+
+// NewCLFEncoder creates an encoder writing access log entries in the Common
+// Log Format of Apache and Nginx, "%h %l %u %t \"%r\" %>s %b", such as
+//
+//	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /a.gif HTTP/1.0" 200 2326
+//
+// The host, identity, user, request, status and size are the values of the
+// fields keyed CLFRemoteAddrKey, CLFIdentKey, CLFRemoteUserKey,
+// CLFRequestKey, CLFStatusKey and CLFBytesSentKey, or "-" when missing, and
+// the time is the time of the entry. Control characters are escaped as
+// \xHH, and '\' and '"' with a backslash. Other fields and the message are
+// left out, as are fields in namespaces. Entries end with cfg.LineEnding.
+func NewCLFEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return &clfEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder(), cfg: &cfg}
+}
+
+func (enc *clfEncoder) OpenNamespace(key string) {
+	enc.MapObjectEncoder.OpenNamespace(key)
+	enc.namespaced = true
+}
+
+func (enc *clfEncoder) Clone() zapcore.Encoder {
+	clone := &clfEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder(), cfg: enc.cfg, namespaced: enc.namespaced}
+	for k, v := range enc.Fields {
+		clone.Fields[k] = v
+	}
+	if enc.namespaced {
+		clone.MapObjectEncoder.OpenNamespace("")
+	}
+	return clone
+}
+
+// value returns the value of the field keyed key, or "-".
+func (enc *clfEncoder) value(key string) string {
+	v, ok := enc.Fields[key]
+	if !ok {
+		return "-"
+	}
+	s := fmt.Sprint(v)
+	if s == "" || (key == CLFBytesSentKey && s == "0") {
+		return "-"
+	}
+	return s
+}
+
+// appendCLFValue appends s to buf with '\' and '"' escaped with a backslash,
+// and control characters as \xHH, as Apache does, so that values cannot
+// end the line or the quoted request early.
+func appendCLFValue(buf *buffer.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	for i := 0; i < len(s); i++ {
+		switch b := s[i]; {
+		case b == '\\' || b == '"':
+			buf.AppendByte('\\')
+			buf.AppendByte(b)
+		case b < 0x20 || b == 0x7f:
+			buf.AppendString(`\x`)
+			buf.AppendByte(hex[b>>4])
+			buf.AppendByte(hex[b&0xf])
+		default:
+			buf.AppendByte(b)
+		}
+	}
+}
+
+func (enc *clfEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := enc.Clone().(*clfEncoder)
+	for i := range fields {
+		fields[i].AddTo(final)
+	}
+
+	buf := bufferPool.Get()
+	for _, key := range []string{CLFRemoteAddrKey, CLFIdentKey, CLFRemoteUserKey} {
+		appendCLFValue(buf, strings.Replace(final.value(key), " ", "-", -1))
+		buf.AppendByte(' ')
+	}
+	buf.AppendByte('[')
+	buf.AppendTime(ent.Time, clfTimeLayout)
+	buf.AppendString(`] "`)
+	appendCLFValue(buf, final.value(CLFRequestKey))
+	buf.AppendString(`" `)
+	appendCLFValue(buf, final.value(CLFStatusKey))
+	buf.AppendByte(' ')
+	appendCLFValue(buf, final.value(CLFBytesSentKey))
+	if enc.cfg.LineEnding != "" {
+		buf.AppendString(enc.cfg.LineEnding)
+	} else {
+		buf.AppendString(zapcore.DefaultLineEnding)
+	}
+	bufferPool.handOff()
+	return buf, nil
+}
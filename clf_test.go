@@ -0,0 +1,76 @@
+package zaptextencoder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func encodeCLF(t *testing.T, enc zapcore.Encoder, ent zapcore.Entry, fields ...zapcore.Field) string {
+	buf, err := enc.EncodeEntry(ent, fields)
+	require.NoError(t, err, "Unexpected CLF encoding error.")
+	defer buf.Free()
+	return buf.String()
+}
+
+func TestCLFEncoder(t *testing.T) {
+	enc := NewCLFEncoder(zapcore.EncoderConfig{})
+	enc.AddString(CLFRemoteAddrKey, "127.0.0.1")
+	ent := zapcore.Entry{
+		Level:   zapcore.InfoLevel,
+		Time:    time.Date(2000, time.October, 10, 13, 55, 36, 0, time.UTC),
+		Message: "request served",
+	}
+
+	assert.Equal(t,
+		`127.0.0.1 - frank [10/Oct/2000:13:55:36 +0000] "GET /apache_pb.gif HTTP/1.0" 200 2326`+"\n",
+		encodeCLF(t, enc, ent,
+			zap.String(CLFIdentKey, ""),
+			zap.String(CLFRemoteUserKey, "frank"),
+			zap.String(CLFRequestKey, "GET /apache_pb.gif HTTP/1.0"),
+			zap.Int(CLFStatusKey, 200),
+			zap.Int64(CLFBytesSentKey, 2326),
+			zap.Duration("elapsed", time.Second),
+			zap.String("user_agent", "curl"),
+		),
+		"Unexpected CLF entry.",
+	)
+
+	assert.Equal(t,
+		`127.0.0.1 - - [10/Oct/2000:13:55:36 +0000] "GET /\"q\" HTTP/1.1" 304 -`+"\n",
+		encodeCLF(t, enc, ent,
+			zap.String(CLFRequestKey, `GET /"q" HTTP/1.1`),
+			zap.Int(CLFStatusKey, 304),
+			zap.Int(CLFBytesSentKey, 0),
+		),
+		"Expected quotes escaped and missing values as '-'.",
+	)
+
+	ns := enc.Clone()
+	ns.OpenNamespace("upstream")
+	assert.Equal(t,
+		`127.0.0.1 - - [10/Oct/2000:13:55:36 +0000] "-" - -`+"\n",
+		encodeCLF(t, ns, ent, zap.String(CLFRequestKey, "GET / HTTP/1.1"), zap.Int(CLFStatusKey, 502)),
+		"Expected fields in namespaces to be left out.",
+	)
+}
+
+func TestCLFEncoderControlCharacters(t *testing.T) {
+	enc := NewCLFEncoder(zapcore.EncoderConfig{})
+	ent := zapcore.Entry{Time: time.Date(2000, time.October, 10, 13, 55, 36, 0, time.UTC)}
+
+	assert.Equal(t,
+		`10.0.0.1\x0d\x0a - bob\x0a1.2.3.4 [10/Oct/2000:13:55:36 +0000] "GET / HTTP/1.1\x0d\x0a127.0.0.1 - - [...] \"GET /admin\x1b\x7f" 200 -`+"\n",
+		encodeCLF(t, enc, ent,
+			zap.String(CLFRemoteAddrKey, "10.0.0.1\r\n"),
+			zap.String(CLFRemoteUserKey, "bob\n1.2.3.4"),
+			zap.String(CLFRequestKey, "GET / HTTP/1.1\r\n127.0.0.1 - - [...] \"GET /admin\x1b\x7f"),
+			zap.Int(CLFStatusKey, 200),
+		),
+		"Expected control characters escaped so that values cannot start a new line.",
+	)
+}
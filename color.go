@@ -0,0 +1,198 @@
+package zaptextencoder
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ANSI escape sequences for the colors used by the encoder.
+const (
+	ColorReset   = "\x1b[0m"
+	ColorRed     = "\x1b[31m"
+	ColorGreen   = "\x1b[32m"
+	ColorYellow  = "\x1b[33m"
+	ColorBlue    = "\x1b[34m"
+	ColorMagenta = "\x1b[35m"
+	ColorCyan    = "\x1b[36m"
+	ColorWhite   = "\x1b[37m"
+	ColorDimGray = "\x1b[90m"
+)
+
+// ColorScheme maps each level to the ANSI escape sequence used for it.
+type ColorScheme map[zapcore.Level]string
+
+// DefaultColorScheme uses the same colors as zapcore.CapitalColorLevelEncoder.
+var DefaultColorScheme = ColorScheme{
+	zapcore.DebugLevel:  ColorMagenta,
+	zapcore.InfoLevel:   ColorBlue,
+	zapcore.WarnLevel:   ColorYellow,
+	zapcore.ErrorLevel:  ColorRed,
+	zapcore.DPanicLevel: ColorRed,
+	zapcore.PanicLevel:  ColorRed,
+	zapcore.FatalLevel:  ColorRed,
+}
+
+// TrueColorScheme maps each level to a 24-bit RGB color.
+type TrueColorScheme map[zapcore.Level][3]uint8
+
+// DefaultTrueColorScheme picks level colors from the Okabe-Ito palette,
+// which stays distinguishable for the common forms of color blindness.
+var DefaultTrueColorScheme = TrueColorScheme{
+	zapcore.DebugLevel:  {204, 121, 167},
+	zapcore.InfoLevel:   {86, 180, 233},
+	zapcore.WarnLevel:   {230, 159, 0},
+	zapcore.ErrorLevel:  {213, 94, 0},
+	zapcore.DPanicLevel: {213, 94, 0},
+	zapcore.PanicLevel:  {213, 94, 0},
+	zapcore.FatalLevel:  {213, 94, 0},
+}
+
+// TrueColorLevelEncoder returns a zapcore.LevelEncoder that serializes a
+// Level to an all-caps string wrapped in the 24-bit color the scheme
+// assigns to it. Levels missing from the scheme are left uncolored.
+func TrueColorLevelEncoder(scheme TrueColorScheme) zapcore.LevelEncoder {
+	colored := make(map[zapcore.Level]string, len(scheme))
+	for l, rgb := range scheme {
+		colored[l] = TrueColor(rgb[0], rgb[1], rgb[2]) + l.CapitalString() + ColorReset
+	}
+	return func(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+		s, ok := colored[l]
+		if !ok {
+			s = l.CapitalString()
+		}
+		enc.AppendString(s)
+	}
+}
+
+// TrueColor returns the ANSI escape sequence setting the 24-bit foreground
+// color r, g, b.
+func TrueColor(r, g, b uint8) string {
+	return "\x1b[38;2;" + strconv.Itoa(int(r)) + ";" + strconv.Itoa(int(g)) + ";" + strconv.Itoa(int(b)) + "m"
+}
+
+// WithMessageColors colorizes the message of each entry with the color the
+// scheme assigns to the entry's level. Levels missing from the scheme are
+// left uncolored.
+func WithMessageColors(scheme ColorScheme) Option {
+	return func(enc *textEncoder) {
+		enc.messageColors = scheme
+	}
+}
+
+// WithTimeColor wraps the encoded timestamp of each entry in the given ANSI
+// escape sequence, such as ColorDimGray.
+func WithTimeColor(color string) Option {
+	return func(enc *textEncoder) {
+		enc.timeColor = color
+	}
+}
+
+// ColorableWriter is implemented by writers that know whether the output
+// they're connected to renders ANSI colors.
+type ColorableWriter interface {
+	IsColorable() bool
+}
+
+// IsTerminal reports whether w renders ANSI colors. Writers implementing
+// ColorableWriter decide for themselves; an *os.File is colorable if it is
+// a character device such as a terminal. Anything else is not.
+func IsTerminal(w io.Writer) bool {
+	if cw, ok := w.(ColorableWriter); ok {
+		return cw.IsColorable()
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// ColorConfig holds per-field color settings.
+type ColorConfig struct {
+	// KeyColorMap maps field keys to the ANSI escape sequence used to
+	// highlight them. The color covers both the key and its value.
+	KeyColorMap map[string]string
+}
+
+// WithColorConfig applies the given per-field colors.
+func WithColorConfig(cc ColorConfig) Option {
+	return func(enc *textEncoder) {
+		enc.keyColors = cc.KeyColorMap
+	}
+}
+
+// ColorEnabled reports whether colors should be written to w. It is the
+// single place deciding this from the environment:
+//
+//   - NO_COLOR set to any non-empty value disables colors (https://no-color.org);
+//   - otherwise FORCE_COLOR set to any non-empty value enables them;
+//   - otherwise TERM=dumb disables them;
+//   - otherwise colors are enabled if w is a terminal, see IsTerminal.
+//
+// A nil w stands for an unknown writer, which doesn't disable colors.
+func ColorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if os.Getenv("FORCE_COLOR") != "" {
+		return true
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return w == nil || IsTerminal(w)
+}
+
+// IsColorDisabled reports whether the environment asks for uncolored output,
+// either by setting NO_COLOR to a non-empty value or with TERM=dumb when
+// FORCE_COLOR isn't set. See ColorEnabled.
+func IsColorDisabled() bool {
+	return !ColorEnabled(nil)
+}
+
+// disableColors drops every color setting of enc, including ANSI sequences
+// written by the configured level encoder.
+func (enc *textEncoder) disableColors() {
+	enc.messageColors = nil
+	enc.timeColor = ""
+	enc.keyColors = nil
+	if enc.EncodeLevel != nil {
+		enc.EncodeLevel = uncoloredLevelEncoder(enc.EncodeLevel)
+	}
+}
+
+// uncoloredLevelEncoder strips ANSI escape sequences from the output of e.
+func uncoloredLevelEncoder(e zapcore.LevelEncoder) zapcore.LevelEncoder {
+	return func(l zapcore.Level, pae zapcore.PrimitiveArrayEncoder) {
+		arr := getSliceEncoder()
+		e(l, arr)
+		for _, elem := range arr.elems {
+			pae.AppendString(stripANSI(fmt.Sprint(elem)))
+		}
+		putSliceEncoder(arr)
+	}
+}
+
+// stripANSI removes ANSI CSI escape sequences, such as colors, from s.
+func stripANSI(s string) string {
+	if strings.IndexByte(s, '\x1b') < 0 {
+		return s
+	}
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\x1b' || i+1 >= len(s) || s[i+1] != '[' {
+			b = append(b, s[i])
+			continue
+		}
+		// Skip parameter and intermediate bytes up to the final byte.
+		for i += 2; i < len(s) && (s[i] < 0x40 || s[i] > 0x7e); i++ {
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,326 @@
+package zaptextencoder
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithMessageColors(t *testing.T) {
+	cfg := zapcore.EncoderConfig{MessageKey: "M"}
+	levels := []zapcore.Level{
+		zapcore.DebugLevel,
+		zapcore.InfoLevel,
+		zapcore.WarnLevel,
+		zapcore.ErrorLevel,
+		zapcore.DPanicLevel,
+		zapcore.PanicLevel,
+		zapcore.FatalLevel,
+	}
+
+	for _, lvl := range levels {
+		t.Run(lvl.String(), func(t *testing.T) {
+			colored := NewTextEncoder(cfg, WithMessageColors(DefaultColorScheme))
+			buf, err := colored.EncodeEntry(zapcore.Entry{Level: lvl, Message: "lob law"}, nil)
+			if assert.NoError(t, err, "Unexpected text encoding error.") {
+				assert.Equal(t, DefaultColorScheme[lvl]+"lob law"+ColorReset+"\n", buf.String(), "Expected a colored message.")
+			}
+			buf.Free()
+
+			plain := NewTextEncoder(cfg)
+			buf, err = plain.EncodeEntry(zapcore.Entry{Level: lvl, Message: "lob law"}, nil)
+			if assert.NoError(t, err, "Unexpected text encoding error.") {
+				assert.Equal(t, "lob law\n", buf.String(), "Expected an uncolored message.")
+			}
+			buf.Free()
+		})
+	}
+
+	t.Run("level missing from scheme", func(t *testing.T) {
+		enc := NewTextEncoder(cfg, WithMessageColors(ColorScheme{zapcore.ErrorLevel: ColorRed}))
+		buf, err := enc.EncodeEntry(zapcore.Entry{Level: zapcore.InfoLevel, Message: "lob law"}, nil)
+		if assert.NoError(t, err, "Unexpected text encoding error.") {
+			assert.Equal(t, "lob law\n", buf.String(), "Expected an uncolored message.")
+		}
+		buf.Free()
+	})
+}
+
+func TestWithTimeColor(t *testing.T) {
+	cfg := zapcore.EncoderConfig{
+		MessageKey:  "M",
+		LevelKey:    "L",
+		TimeKey:     "T",
+		EncodeLevel: zapcore.CapitalLevelEncoder,
+		EncodeTime:  zapcore.ISO8601TimeEncoder,
+	}
+	ent := zapcore.Entry{
+		Level:   zapcore.ErrorLevel,
+		Time:    time.Date(2018, 6, 19, 16, 33, 42, 99, time.UTC),
+		Message: "lob law",
+	}
+
+	enc := NewTextEncoder(cfg, WithTimeColor(ColorDimGray))
+	buf, err := enc.EncodeEntry(ent, nil)
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		out := buf.String()
+		assert.Equal(t, ColorDimGray+"2018-06-19T16:33:42.000Z"+ColorReset+"  ERROR  lob law\n", out, "Unexpected colored time.")
+		assert.Equal(t, 0, strings.Index(out, ColorDimGray), "Expected the time color at the start of the entry.")
+		assert.Equal(t, len(ColorDimGray)+len("2018-06-19T16:33:42.000Z"), strings.Index(out, ColorReset), "Expected a reset right after the time.")
+	}
+	buf.Free()
+
+	t.Run("level colors are independent", func(t *testing.T) {
+		cfg := cfg
+		cfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		enc := NewTextEncoder(cfg, WithTimeColor(ColorCyan))
+		buf, err := enc.EncodeEntry(ent, nil)
+		if assert.NoError(t, err, "Unexpected text encoding error.") {
+			assert.Equal(t, ColorCyan+"2018-06-19T16:33:42.000Z"+ColorReset+"  "+ColorRed+"ERROR"+ColorReset+"  lob law\n", buf.String(), "Unexpected colored time.")
+		}
+		buf.Free()
+	})
+}
+
+type colorableWriter struct {
+	bytes.Buffer
+	colorable bool
+}
+
+func (w *colorableWriter) IsColorable() bool { return w.colorable }
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.CreateTemp("", "zaptextencoder")
+	if !assert.NoError(t, err, "Unexpected error creating a temporary file.") {
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	assert.False(t, IsTerminal(&bytes.Buffer{}), "Expected a bytes.Buffer not to be a terminal.")
+	assert.False(t, IsTerminal(f), "Expected a regular file not to be a terminal.")
+	assert.True(t, IsTerminal(&colorableWriter{colorable: true}), "Expected ColorableWriter to decide.")
+	assert.False(t, IsTerminal(&colorableWriter{colorable: false}), "Expected ColorableWriter to decide.")
+}
+
+func TestWithColorConfig(t *testing.T) {
+	cfg := zapcore.EncoderConfig{MessageKey: "M"}
+	ent := zapcore.Entry{Level: zapcore.ErrorLevel, Message: "lob law"}
+	fields := []zapcore.Field{
+		zap.String("error", "boom"),
+		zap.Int("latency_ms", 42),
+		zap.Bool("retry", true),
+		zap.Ints("ids", []int{1, 2}),
+	}
+
+	tests := []struct {
+		desc     string
+		cc       ColorConfig
+		expected string
+	}{
+		{
+			desc: "error is red",
+			cc:   ColorConfig{KeyColorMap: map[string]string{"error": ColorRed}},
+			expected: "lob law  " + ColorRed + `error="boom"` + ColorReset +
+				"  latency_ms=42  retry=true  ids=[1,2]\n",
+		},
+		{
+			desc: "several keys",
+			cc:   ColorConfig{KeyColorMap: map[string]string{"latency_ms": ColorYellow, "ids": ColorCyan}},
+			expected: `lob law  error="boom"  ` + ColorYellow + "latency_ms=42" + ColorReset +
+				"  retry=true  " + ColorCyan + "ids=[1,2]" + ColorReset + "\n",
+		},
+		{
+			desc:     "empty map",
+			cc:       ColorConfig{KeyColorMap: map[string]string{}},
+			expected: "lob law  error=\"boom\"  latency_ms=42  retry=true  ids=[1,2]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(cfg, WithColorConfig(tt.cc))
+			buf, err := enc.EncodeEntry(ent, fields)
+			if assert.NoError(t, err, "Unexpected text encoding error.") {
+				assert.Equal(t, tt.expected, buf.String(), "Unexpected field colors.")
+			}
+			buf.Free()
+		})
+	}
+}
+
+// setEnv sets an environment variable for the duration of the test.
+func setEnv(t *testing.T, key, val string) {
+	old, ok := os.LookupEnv(key)
+	os.Setenv(key, val)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestIsColorDisabled(t *testing.T) {
+	tests := []struct {
+		desc       string
+		noColor    string
+		forceColor string
+		term       string
+		expected   bool
+	}{
+		{"unset", "", "", "xterm", false},
+		{"NO_COLOR", "1", "", "xterm", true},
+		{"NO_COLOR any value", "false", "", "xterm", true},
+		{"dumb terminal", "", "", "dumb", true},
+		{"FORCE_COLOR on dumb terminal", "", "1", "dumb", false},
+		{"NO_COLOR wins over FORCE_COLOR", "1", "1", "xterm", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			setEnv(t, "NO_COLOR", tt.noColor)
+			setEnv(t, "FORCE_COLOR", tt.forceColor)
+			setEnv(t, "TERM", tt.term)
+			assert.Equal(t, tt.expected, IsColorDisabled(), "Unexpected IsColorDisabled result.")
+		})
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		desc       string
+		noColor    string
+		forceColor string
+		w          io.Writer
+		expected   bool
+	}{
+		{"unknown writer", "", "", nil, true},
+		{"terminal", "", "", &colorableWriter{colorable: true}, true},
+		{"not a terminal", "", "", &bytes.Buffer{}, false},
+		{"FORCE_COLOR on a non-terminal", "", "1", &bytes.Buffer{}, true},
+		{"NO_COLOR on a terminal", "1", "", &colorableWriter{colorable: true}, false},
+		{"NO_COLOR wins over FORCE_COLOR", "1", "1", &bytes.Buffer{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			setEnv(t, "NO_COLOR", tt.noColor)
+			setEnv(t, "FORCE_COLOR", tt.forceColor)
+			setEnv(t, "TERM", "xterm")
+			assert.Equal(t, tt.expected, ColorEnabled(tt.w), "Unexpected ColorEnabled result.")
+		})
+	}
+}
+
+func TestForceColorWithNoColor(t *testing.T) {
+	setEnv(t, "NO_COLOR", "1")
+	setEnv(t, "FORCE_COLOR", "1")
+
+	enc := NewTextEncoder(zapcore.EncoderConfig{MessageKey: "M"}, WithMessageColors(DefaultColorScheme))
+	buf, err := enc.EncodeEntry(zapcore.Entry{Level: zapcore.ErrorLevel, Message: "lob law"}, nil)
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		assert.Equal(t, "lob law\n", buf.String(), "Expected NO_COLOR to win over FORCE_COLOR.")
+	}
+	buf.Free()
+}
+
+func TestNoColor(t *testing.T) {
+	setEnv(t, "NO_COLOR", "1")
+
+	cfg := zapcore.EncoderConfig{
+		MessageKey:  "M",
+		LevelKey:    "L",
+		TimeKey:     "T",
+		EncodeLevel: zapcore.CapitalColorLevelEncoder,
+		EncodeTime:  zapcore.ISO8601TimeEncoder,
+	}
+	enc := NewTextEncoder(cfg,
+		WithMessageColors(DefaultColorScheme),
+		WithTimeColor(ColorDimGray),
+		WithColorConfig(ColorConfig{KeyColorMap: map[string]string{"error": ColorRed}}),
+	)
+	buf, err := enc.EncodeEntry(zapcore.Entry{
+		Level:   zapcore.ErrorLevel,
+		Time:    time.Date(2018, 6, 19, 16, 33, 42, 99, time.UTC),
+		Message: "lob law",
+	}, []zapcore.Field{zap.String("error", "boom")})
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		assert.Equal(t, "2018-06-19T16:33:42.000Z  ERROR  lob law  error=\"boom\"\n", buf.String(), "Unexpected output with NO_COLOR set.")
+		assert.NotContains(t, buf.String(), "\x1b", "Expected no escape sequences with NO_COLOR set.")
+	}
+	buf.Free()
+}
+
+func TestStripANSI(t *testing.T) {
+	tests := map[string]string{
+		"":                            "",
+		"INFO":                        "INFO",
+		"\x1b[34mINFO\x1b[0m":         "INFO",
+		"\x1b[38;2;1;2;3mINFO\x1b[0m": "INFO",
+		"a\x1bb":                      "a\x1bb",
+		"trailing escape\x1b":         "trailing escape\x1b",
+		"unterminated \x1b[31":        "unterminated ",
+	}
+	for input, expected := range tests {
+		assert.Equal(t, expected, stripANSI(input), "Unexpected result stripping %q.", input)
+	}
+}
+
+func TestTrueColorLevelEncoder(t *testing.T) {
+	tests := []struct {
+		lvl      zapcore.Level
+		expected string
+	}{
+		{zapcore.DebugLevel, "\x1b[38;2;204;121;167mDEBUG\x1b[0m"},
+		{zapcore.InfoLevel, "\x1b[38;2;86;180;233mINFO\x1b[0m"},
+		{zapcore.WarnLevel, "\x1b[38;2;230;159;0mWARN\x1b[0m"},
+		{zapcore.ErrorLevel, "\x1b[38;2;213;94;0mERROR\x1b[0m"},
+		{zapcore.DPanicLevel, "\x1b[38;2;213;94;0mDPANIC\x1b[0m"},
+		{zapcore.PanicLevel, "\x1b[38;2;213;94;0mPANIC\x1b[0m"},
+		{zapcore.FatalLevel, "\x1b[38;2;213;94;0mFATAL\x1b[0m"},
+	}
+
+	encodeLevel := TrueColorLevelEncoder(DefaultTrueColorScheme)
+	for _, tt := range tests {
+		t.Run(tt.lvl.String(), func(t *testing.T) {
+			arr := &sliceArrayEncoder{}
+			encodeLevel(tt.lvl, arr)
+			assert.Equal(t, []interface{}{tt.expected}, arr.elems, "Unexpected true color level.")
+		})
+	}
+
+	t.Run("level missing from scheme", func(t *testing.T) {
+		arr := &sliceArrayEncoder{}
+		TrueColorLevelEncoder(TrueColorScheme{zapcore.ErrorLevel: {255, 0, 0}})(zapcore.InfoLevel, arr)
+		assert.Equal(t, []interface{}{"INFO"}, arr.elems, "Expected an uncolored level.")
+	})
+
+	t.Run("in EncoderConfig", func(t *testing.T) {
+		cfg := zapcore.EncoderConfig{
+			MessageKey:  "M",
+			LevelKey:    "L",
+			EncodeLevel: TrueColorLevelEncoder(TrueColorScheme{zapcore.ErrorLevel: {255, 0, 0}}),
+		}
+		buf, err := NewTextEncoder(cfg).EncodeEntry(zapcore.Entry{Level: zapcore.ErrorLevel, Message: "lob law"}, nil)
+		if assert.NoError(t, err, "Unexpected text encoding error.") {
+			assert.Equal(t, "\x1b[38;2;255;0;0mERROR\x1b[0m  lob law\n", buf.String(), "Unexpected encoded entry.")
+		}
+		buf.Free()
+
+		setEnv(t, "NO_COLOR", "1")
+		buf, err = NewTextEncoder(cfg).EncodeEntry(zapcore.Entry{Level: zapcore.ErrorLevel, Message: "lob law"}, nil)
+		if assert.NoError(t, err, "Unexpected text encoding error.") {
+			assert.Equal(t, "ERROR  lob law\n", buf.String(), "Expected NO_COLOR to strip true colors.")
+		}
+		buf.Free()
+	})
+}
@@ -0,0 +1,83 @@
+package zaptextencoder
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// conditionalValue marks a field created by ConditionalField.
+type conditionalValue struct {
+	minLevel zapcore.Level
+	field    zapcore.Field
+}
+
+// MarshalLogObject writes the field as an object of its own to encoders
+// which don't know about levels.
+func (v conditionalValue) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	v.field.AddTo(enc)
+	return nil
+}
+
+// ConditionalField logs field only in entries at minLevel or above, such as
+// to add verbose context to errors alone. Given to logger.With, the field is
+// written after the other fields added with it, and within an object it is
+// always written. Other encoders always write it too, as an object holding
+// the field under the same key.
+func ConditionalField(minLevel zapcore.Level, field zapcore.Field) zap.Field {
+	return zap.Object(field.Key, conditionalValue{minLevel, field})
+}
+
+// addConditional holds back a ConditionalField given to logger.With until
+// there is an entry to compare its level with.
+func (enc *textEncoder) addConditional(v conditionalValue) error {
+	if enc.depth > 0 {
+		v.field.AddTo(enc)
+		return nil
+	}
+	enc.conditional = append(enc.conditional, v)
+	return nil
+}
+
+// filterConditional returns fields with every ConditionalField replaced by
+// its field, or dropped if level is below its minimum.
+func filterConditional(level zapcore.Level, fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		cv, ok := f.Interface.(conditionalValue)
+		if !ok || f.Type != zapcore.ObjectMarshalerType {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		if level >= cv.minLevel {
+			out = append(out, cv.field)
+		}
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// encodeConditionalEntry encodes an entry with a clone of enc holding the
+// conditional fields added to enc that apply to it.
+func (enc *textEncoder) encodeConditionalEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	clone := enc.cloneContext(enc.buffers().Get())
+	// The entry was counted by enc.
+	clone.conditional, clone.levelCounters = nil, nil
+	for _, cv := range enc.conditional {
+		if ent.Level >= cv.minLevel {
+			cv.field.AddTo(clone)
+		}
+	}
+	buf, err := clone.EncodeEntry(ent, fields)
+	enc.buffers().Put(clone.buf)
+	putTextEncoder(clone)
+	return buf, err
+}
@@ -0,0 +1,61 @@
+package zaptextencoder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestConditionalField(t *testing.T) {
+	body := ConditionalField(zap.ErrorLevel, zap.String("body", "{}"))
+
+	assertEncodedEntry(t, "error  lob law  k=1  body=\"{}\"  n=2\n", nil, zap.Int("k", 1), body, zap.Int("n", 2))
+	assertEncodedEntry(t, "error  lob law  k=1\n", nil, zap.Int("k", 1), ConditionalField(zap.FatalLevel, zap.String("body", "{}")))
+	assertEncodedEntry(t, "error  lob law  lazy=1\n", nil,
+		ConditionalField(zap.ErrorLevel, LazyField("lazy", func() interface{} { return 1 })))
+	assertEncodedEntry(t, "error  lob law  obj={k=1  body=\"{}\"}\n", nil,
+		zap.Object("obj", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddInt("k", 1)
+			return enc.AddObject("body", body.Interface.(zapcore.ObjectMarshaler))
+		})))
+	assertEncodedEntry(t, "error  lob law\n", nil,
+		ConditionalField(zap.FatalLevel, LazyField("lazy", func() interface{} {
+			t.Error("Expected the lazy field not to be evaluated.")
+			return 1
+		})))
+}
+
+func TestConditionalFieldLogger(t *testing.T) {
+	var out bytes.Buffer
+	enc := NewTextEncoder(_optionsEncoderConfig)
+	logger := zap.New(zapcore.NewCore(enc, zapcore.AddSync(&out), zap.DebugLevel))
+	body := ConditionalField(zap.ErrorLevel, zap.String("body", "{}"))
+
+	logger.Info("lob law", body)
+	logger.Error("lob law", body)
+	assert.Equal(t, "info   lob law\nerror  lob law  body=\"{}\"\n", out.String(), "Unexpected entry fields.")
+
+	out.Reset()
+	child := logger.With(zap.Int("a", 1), body, zap.Int("b", 2))
+	child.Info("lob law")
+	child.Error("lob law")
+	// Clones of the encoder keep the threshold.
+	grandchild := child.With(zap.Int("c", 3))
+	grandchild.Info("lob law")
+	grandchild.Error("lob law")
+	logger.Error("lob law")
+	assert.Equal(t, "info   a=1  b=2  lob law\n"+
+		"error  a=1  b=2  body=\"{}\"  lob law\n"+
+		"info   a=1  b=2  c=3  lob law\n"+
+		"error  a=1  b=2  c=3  body=\"{}\"  lob law\n"+
+		"error  lob law\n", out.String(), "Unexpected context fields.")
+
+	// Other encoders always write the field.
+	core, logs := observer.New(zap.DebugLevel)
+	zap.New(core).With(body).Info("lob law")
+	assert.Equal(t, map[string]interface{}{"body": "{}"}, logs.All()[0].ContextMap()["body"], "Expected other encoders to write the field.")
+}
@@ -0,0 +1,62 @@
+package zaptextencoder
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ContextExtractor returns the fields to log for a context.Context, such
+// as the trace ID it carries.
+type ContextExtractor func(ctx context.Context) []zapcore.Field
+
+// WithContextExtractor sets the function turning each ContextField of an
+// entry into the fields written in its place.
+func WithContextExtractor(ext ContextExtractor) Option {
+	return func(enc *textEncoder) {
+		enc.contextExtractor = ext
+	}
+}
+
+// contextValue marks a field created by ContextField.
+type contextValue struct {
+	ctx context.Context
+}
+
+// ContextField carries ctx to the encoder, which replaces it with the
+// fields returned by the extractor set by WithContextExtractor. It is
+// written as nothing by other encoders, by encoders with no extractor, and
+// when given to logger.With rather than to a logging call.
+func ContextField(ctx context.Context) zap.Field {
+	return zap.Field{Type: zapcore.SkipType, Interface: contextValue{ctx}}
+}
+
+// extractContexts returns fields with every ContextField replaced by the
+// fields extracted from its context.
+func (enc *textEncoder) extractContexts(fields []zapcore.Field) []zapcore.Field {
+	if enc.contextExtractor == nil {
+		return fields
+	}
+	var out []zapcore.Field
+	for i, f := range fields {
+		cv, ok := f.Interface.(contextValue)
+		if !ok || f.Type != zapcore.SkipType {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, i, len(fields)+4)
+			copy(out, fields[:i])
+		}
+		if cv.ctx != nil {
+			out = append(out, enc.contextExtractor(cv.ctx)...)
+		}
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
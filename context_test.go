@@ -0,0 +1,30 @@
+package zaptextencoder
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type traceIDKey struct{}
+
+func extractTraceID(ctx context.Context) []zapcore.Field {
+	if id, ok := ctx.Value(traceIDKey{}).(string); ok {
+		return []zapcore.Field{zap.String("trace_id", id)}
+	}
+	return nil
+}
+
+func TestContextField(t *testing.T) {
+	ctx := context.WithValue(context.Background(), traceIDKey{}, "abc123")
+	opts := []Option{WithContextExtractor(extractTraceID)}
+
+	assertEncodedEntry(t, "error  lob law  k=1  trace_id=\"abc123\"  n=2\n", opts,
+		zap.Int("k", 1), ContextField(ctx), zap.Int("n", 2))
+	assertEncodedEntry(t, "error  lob law  trace_id=\"abc123\"\n", opts, ContextField(ctx))
+	assertEncodedEntry(t, "error  lob law  k=1\n", opts, zap.Int("k", 1), ContextField(context.Background()))
+	assertEncodedEntry(t, "error  lob law  k=1\n", opts, zap.Int("k", 1), ContextField(nil))
+	assertEncodedEntry(t, "error  lob law  k=1\n", nil, zap.Int("k", 1), ContextField(ctx))
+}
@@ -0,0 +1,158 @@
+package zaptextencoder
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// DeduplicateStrategy chooses which field to keep when a top-level key is
+// repeated in an entry, across the fields added by logger.With and those of
+// the entry itself.
+type DeduplicateStrategy int
+
+const (
+	// DeduplicateNone keeps every field. It is the default.
+	DeduplicateNone DeduplicateStrategy = iota
+	// DeduplicateLastWins keeps the last field of each key, so that a field
+	// added by logger.With can be overridden by a later With call or by a
+	// field of the entry itself.
+	DeduplicateLastWins
+	// DeduplicateFirstWins keeps the first field of each key, so that a
+	// field added by logger.With cannot be overridden.
+	DeduplicateFirstWins
+)
+
+// WithDeduplicateStrategy sets how repeated top-level keys are handled.
+func WithDeduplicateStrategy(s DeduplicateStrategy) Option {
+	return func(enc *textEncoder) {
+		enc.dedup = s
+	}
+}
+
+// WithDeduplicateFields keeps only the last field of each top-level key in
+// an entry. It is a shorthand for DeduplicateLastWins.
+func WithDeduplicateFields(enabled bool) Option {
+	if enabled {
+		return WithDeduplicateStrategy(DeduplicateLastWins)
+	}
+	return WithDeduplicateStrategy(DeduplicateNone)
+}
+
+// fieldSpan records where a top-level field starts in the buffer of an
+// encoder. The span of every field but the first starts with its separator.
+type fieldSpan struct {
+	key   string
+	start int
+}
+
+// recordField notes the start of a top-level field about to be written.
+func (enc *textEncoder) recordField(key string) {
+	if enc.dedup != DeduplicateNone && enc.depth == 0 {
+		enc.spans = append(enc.spans, fieldSpan{key: key, start: enc.contextLen()})
+	}
+}
+
+// deduplicate returns the context of enc and the fields of an entry with
+// repeated keys removed according to the strategy of enc.
+func (enc *textEncoder) deduplicate(fields []zapcore.Field) ([]byte, []zapcore.Field) {
+	seen := make(map[string]struct{}, len(fields)+len(enc.spans))
+	keep := func(key string) bool {
+		if _, ok := seen[key]; ok {
+			return false
+		}
+		seen[key] = struct{}{}
+		return true
+	}
+
+	keys := enc.fieldKeys(fields)
+	keepSpans := make([]bool, len(enc.spans))
+	keepFields := make([]bool, len(fields))
+	if enc.dedup == DeduplicateFirstWins {
+		for i := range enc.spans {
+			keepSpans[i] = keep(enc.spans[i].key)
+		}
+		for i := range fields {
+			keepFields[i] = !dedupable(fields[i]) || keep(keys[i])
+		}
+	} else {
+		for i := len(fields) - 1; i >= 0; i-- {
+			keepFields[i] = !dedupable(fields[i]) || keep(keys[i])
+		}
+		for i := len(enc.spans) - 1; i >= 0; i-- {
+			keepSpans[i] = keep(enc.spans[i].key)
+		}
+	}
+	return enc.keepSpans(keepSpans), filterFields(fields, keepFields)
+}
+
+// fieldKeys returns the key each field of an entry is written under, with
+// the namespaces open in enc and those opened by earlier fields of the entry.
+func (enc *textEncoder) fieldKeys(fields []zapcore.Field) []string {
+	keys := make([]string, len(fields))
+	prefix := enc.namespacedKey("")
+	for i := range fields {
+		if fields[i].Type == zapcore.NamespaceType {
+			prefix += fields[i].Key + enc.namespaceSeparator()
+			continue
+		}
+		keys[i] = prefix + fields[i].Key
+	}
+	return keys
+}
+
+// keepSpans returns the context of enc holding only the fields whose span is
+// kept. The context itself is returned if every span is kept.
+func (enc *textEncoder) keepSpans(keep []bool) []byte {
+	ctx := enc.context()
+	if allTrue(keep) {
+		return ctx
+	}
+	out := make([]byte, 0, len(ctx))
+	for i, span := range enc.spans {
+		if !keep[i] {
+			continue
+		}
+		end := len(ctx)
+		if i+1 < len(enc.spans) {
+			end = enc.spans[i+1].start
+		}
+		field := ctx[span.start:end]
+		if len(out) == 0 && i > 0 {
+			field = field[len(enc.separator):]
+		}
+		out = append(out, field...)
+	}
+	return out
+}
+
+// filterFields returns the fields that are kept, or fields itself if every
+// field is kept.
+func filterFields(fields []zapcore.Field, keep []bool) []zapcore.Field {
+	if allTrue(keep) {
+		return fields
+	}
+	kept := make([]zapcore.Field, 0, len(fields))
+	for i := range fields {
+		if keep[i] {
+			kept = append(kept, fields[i])
+		}
+	}
+	return kept
+}
+
+func allTrue(bs []bool) bool {
+	for _, b := range bs {
+		if !b {
+			return false
+		}
+	}
+	return true
+}
+
+// dedupable reports whether f is a keyed field subject to deduplication.
+func dedupable(f zapcore.Field) bool {
+	switch f.Type {
+	case zapcore.SkipType, zapcore.NamespaceType:
+		return false
+	}
+	return true
+}
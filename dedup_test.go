@@ -0,0 +1,156 @@
+package zaptextencoder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithDeduplicateFields(t *testing.T) {
+	opts := []Option{WithDeduplicateFields(true)}
+
+	assertEncodedEntry(t, "error  lob law  key=\"second\"\n", opts,
+		zap.String("key", "first"),
+		zap.String("key", "second"),
+	)
+	assertEncodedEntry(t, "error  lob law  a=1  key=\"second\"  b=2\n", opts,
+		zap.String("key", "first"),
+		zap.Int("a", 1),
+		zap.String("key", "second"),
+		zap.Int("b", 2),
+	)
+	assertEncodedEntry(t, "error  lob law  obj={key=1  key=2}\n", opts,
+		zap.Object("obj", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddInt("key", 1)
+			enc.AddInt("key", 2)
+			return nil
+		})),
+	)
+}
+
+func TestWithDeduplicateFieldsNamespace(t *testing.T) {
+	opts := []Option{WithDeduplicateFields(true)}
+
+	assertEncodedEntry(t, "error  lob law  a=1  ns.a=\"2\"\n", opts,
+		zap.Int("a", 1),
+		zap.Namespace("ns"),
+		zap.String("a", "2"),
+	)
+	assertEncodedEntry(t, "error  lob law  a=1  ns.a=3\n", opts,
+		zap.Int("a", 1),
+		zap.Namespace("ns"),
+		zap.Int("a", 2),
+		zap.Int("a", 3),
+	)
+}
+
+func TestWithDeduplicateFieldsContext(t *testing.T) {
+	tests := []struct {
+		desc     string
+		context  []zapcore.Field
+		fields   []zapcore.Field
+		expected string
+	}{
+		{
+			desc:     "entry field overrides context",
+			context:  []zapcore.Field{zap.String("key", "base"), zap.Int("a", 1)},
+			fields:   []zapcore.Field{zap.String("key", "override")},
+			expected: "error  a=1  lob law  key=\"override\"\n",
+		},
+		{
+			desc:     "later With overrides earlier With",
+			context:  []zapcore.Field{zap.Int("a", 1), zap.String("key", "base"), zap.String("key", "with")},
+			expected: "error  a=1  key=\"with\"  lob law\n",
+		},
+		{
+			desc:     "first context field dropped",
+			context:  []zapcore.Field{zap.String("key", "base"), zap.Int("a", 1), zap.Int("b", 2)},
+			fields:   []zapcore.Field{zap.String("key", "override")},
+			expected: "error  a=1  b=2  lob law  key=\"override\"\n",
+		},
+		{
+			desc:     "no duplicates",
+			context:  []zapcore.Field{zap.Int("a", 1)},
+			fields:   []zapcore.Field{zap.Int("b", 2)},
+			expected: "error  a=1  lob law  b=2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(_optionsEncoderConfig, WithDeduplicateFields(true))
+			for _, f := range tt.context {
+				enc = enc.Clone()
+				f.AddTo(enc)
+			}
+			buf, err := enc.EncodeEntry(_optionsEntry, tt.fields)
+			if assert.NoError(t, err, "Unexpected text encoding error.") {
+				assert.Equal(t, tt.expected, buf.String(), "Incorrect encoded text entry.")
+			}
+			buf.Free()
+		})
+	}
+}
+
+func TestWithDeduplicateFieldsLogger(t *testing.T) {
+	var out bytes.Buffer
+	enc := NewTextEncoder(_optionsEncoderConfig, WithDeduplicateFields(true))
+	logger := zap.New(zapcore.NewCore(enc, zapcore.AddSync(&out), zap.DebugLevel))
+
+	base := logger.With(zap.String("key", "base"))
+	// Both children share the context of base, and must not see each
+	// other's fields.
+	a := base.With(zap.String("key", "a"))
+	b := base.With(zap.String("key", "b"))
+	a.Error("lob law")
+	b.Error("lob law", zap.String("key", "entry"))
+	base.Error("lob law")
+
+	assert.Equal(t,
+		"error  key=\"a\"  lob law\n"+
+			"error  lob law  key=\"entry\"\n"+
+			"error  key=\"base\"  lob law\n",
+		out.String())
+}
+
+func TestWithDeduplicateStrategy(t *testing.T) {
+	tests := []struct {
+		desc     string
+		strategy DeduplicateStrategy
+		expected string
+	}{
+		{"none", DeduplicateNone, "error  key=\"base\"  key=\"with\"  lob law  key=\"override\"  key=\"again\"\n"},
+		{"last wins", DeduplicateLastWins, "error  lob law  key=\"again\"\n"},
+		{"first wins", DeduplicateFirstWins, "error  key=\"base\"  lob law\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(_optionsEncoderConfig, WithDeduplicateStrategy(tt.strategy))
+			enc.AddString("key", "base")
+			enc = enc.Clone()
+			enc.AddString("key", "with")
+			buf, err := enc.EncodeEntry(_optionsEntry, []zapcore.Field{
+				zap.String("key", "override"),
+				zap.String("key", "again"),
+			})
+			if assert.NoError(t, err, "Unexpected text encoding error.") {
+				assert.Equal(t, tt.expected, buf.String(), "Incorrect encoded text entry.")
+			}
+			buf.Free()
+		})
+	}
+}
+
+func TestDeduplicateFirstWinsLogger(t *testing.T) {
+	var out bytes.Buffer
+	enc := NewTextEncoder(_optionsEncoderConfig, WithDeduplicateStrategy(DeduplicateFirstWins))
+	logger := zap.New(zapcore.NewCore(enc, zapcore.AddSync(&out), zap.DebugLevel))
+
+	logger.With(zap.String("key", "base"), zap.Int("a", 1)).
+		Error("lob law", zap.String("key", "override"), zap.Int("b", 2))
+	assert.Equal(t, "error  key=\"base\"  a=1  lob law  b=2\n", out.String())
+}
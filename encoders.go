@@ -0,0 +1,119 @@
+package zaptextencoder
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// HumanDurationEncoder serializes a time.Duration with its String method,
+// such as "1ms", "2.5s" or "1m30s".
+func HumanDurationEncoder(d time.Duration, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(d.String())
+}
+
+// PadAlign selects the side of a padded column its text is aligned to.
+type PadAlign int
+
+const (
+	// AlignLeft pads the text with spaces on its right.
+	AlignLeft PadAlign = iota
+	// AlignRight pads the text with spaces on its left.
+	AlignRight
+)
+
+// PaddedLevelEncoder returns a LevelEncoder writing the capitalized level,
+// such as "INFO", padded with spaces to width characters so that the level
+// column lines up. Levels longer than width are written as is.
+func PaddedLevelEncoder(width int, align PadAlign) zapcore.LevelEncoder {
+	return func(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(pad(l.CapitalString(), width, align))
+	}
+}
+
+// pad pads s with spaces to width runes.
+func pad(s string, width int, align PadAlign) string {
+	n := width - utf8.RuneCountInString(s)
+	if n <= 0 {
+		return s
+	}
+	if align == AlignRight {
+		return strings.Repeat(" ", n) + s
+	}
+	return s + strings.Repeat(" ", n)
+}
+
+// PaddedNameEncoder returns a NameEncoder writing the logger name padded
+// with spaces on its right to width characters. Longer names are written as
+// is, or cut to width characters ending with "…" if truncate is set.
+func PaddedNameEncoder(width int, truncate bool) zapcore.NameEncoder {
+	return func(name string, enc zapcore.PrimitiveArrayEncoder) {
+		if truncate && width > 0 && utf8.RuneCountInString(name) > width {
+			runes := []rune(name)
+			name = string(runes[:width-1]) + "…"
+		}
+		enc.AppendString(pad(name, width, AlignLeft))
+	}
+}
+
+// EpochMillisTimeEncoder serializes a time.Time as a floating-point number
+// of milliseconds since the Unix epoch, such as 1500.5 for 1.5005s.
+func EpochMillisTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendFloat64(epochUnits(t, time.Millisecond))
+}
+
+// EpochMicrosTimeEncoder serializes a time.Time as a floating-point number
+// of microseconds since the Unix epoch, such as 1500000.5 for 1.5000005s.
+func EpochMicrosTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendFloat64(epochUnits(t, time.Microsecond))
+}
+
+// epochUnits returns the time since the Unix epoch in units of unit. The
+// whole seconds and the nanoseconds are converted apart, as nanoseconds
+// since the epoch no longer fit the mantissa of a float64.
+func epochUnits(t time.Time, unit time.Duration) float64 {
+	perSecond := float64(time.Second / unit)
+	return float64(t.Unix())*perSecond + float64(t.Nanosecond())/float64(unit)
+}
+
+// RFC3339TimeEncoder serializes a time.Time as an RFC 3339 string with
+// second precision, such as "2006-01-02T15:04:05Z07:00".
+func RFC3339TimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(t.Format(time.RFC3339))
+}
+
+// RFC3339NanoTimeEncoder serializes a time.Time as an RFC 3339 string with
+// nanosecond precision, trailing zeros removed, as in time.RFC3339Nano.
+func RFC3339NanoTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(t.Format(time.RFC3339Nano))
+}
+
+// RFC3339PrecisionTimeEncoder returns a TimeEncoder serializing a
+// time.Time as an RFC 3339 string truncated to precision, which should be
+// a power of ten such as time.Millisecond, with a fixed number of
+// fractional digits, such as "2006-01-02T15:04:05.000Z07:00". Precisions of
+// a second or more write no fraction; zero or less means nanoseconds.
+func RFC3339PrecisionTimeEncoder(precision time.Duration) zapcore.TimeEncoder {
+	if precision <= 0 {
+		precision = time.Nanosecond
+	}
+	digits := 0
+	for unit := time.Second; unit > precision && digits < 9; unit /= 10 {
+		digits++
+	}
+	layout := time.RFC3339
+	if digits > 0 {
+		layout = "2006-01-02T15:04:05." + strings.Repeat("0", digits) + "Z07:00"
+	}
+	return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(t.Truncate(precision).Format(layout))
+	}
+}
+
+// SyslogTimeEncoder serializes a time.Time in the timestamp format of
+// syslog, RFC 3164, such as "Jan  2 15:04:05", the day padded with a space.
+func SyslogTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(t.Format(time.Stamp))
+}
@@ -0,0 +1,223 @@
+package zaptextencoder
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestHumanDurationEncoder(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		expected string
+	}{
+		{time.Nanosecond, "1ns"},
+		{time.Microsecond, "1µs"},
+		{time.Millisecond, "1ms"},
+		{time.Second, "1s"},
+		{2500 * time.Millisecond, "2.5s"},
+		{90 * time.Second, "1m30s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			arr := &sliceArrayEncoder{}
+			HumanDurationEncoder(tt.d, arr)
+			assert.Equal(t, []interface{}{tt.expected}, arr.elems, "Unexpected human-readable duration.")
+			assert.Equal(t, tt.d.String(), tt.expected, "Expected time.Duration.String semantics.")
+		})
+	}
+
+	t.Run("in EncoderConfig", func(t *testing.T) {
+		assertOutput(t, zapcore.EncoderConfig{EncodeDuration: HumanDurationEncoder}, `k="1m30s"`, func(e zapcore.Encoder) {
+			e.AddDuration("k", 90*time.Second)
+		})
+	})
+}
+
+func TestPaddedLevelEncoder(t *testing.T) {
+	levels := []zapcore.Level{
+		zapcore.DebugLevel,
+		zapcore.InfoLevel,
+		zapcore.WarnLevel,
+		zapcore.ErrorLevel,
+		zapcore.DPanicLevel,
+		zapcore.PanicLevel,
+		zapcore.FatalLevel,
+	}
+	for _, align := range []PadAlign{AlignLeft, AlignRight} {
+		for _, l := range levels {
+			arr := &sliceArrayEncoder{}
+			PaddedLevelEncoder(7, align)(l, arr)
+			if assert.Len(t, arr.elems, 1) {
+				s := arr.elems[0].(string)
+				assert.Len(t, s, 7, "Unexpected width of padded level %q.", s)
+				assert.Equal(t, l.CapitalString(), strings.TrimSpace(s))
+			}
+		}
+	}
+
+	arr := &sliceArrayEncoder{}
+	PaddedLevelEncoder(5, AlignRight)(zapcore.InfoLevel, arr)
+	PaddedLevelEncoder(5, AlignLeft)(zapcore.InfoLevel, arr)
+	PaddedLevelEncoder(3, AlignLeft)(zapcore.ErrorLevel, arr)
+	assert.Equal(t, []interface{}{" INFO", "INFO ", "ERROR"}, arr.elems)
+
+	t.Run("in EncoderConfig", func(t *testing.T) {
+		cfg := zapcore.EncoderConfig{MessageKey: "M", LevelKey: "L", EncodeLevel: PaddedLevelEncoder(5, AlignLeft)}
+		for _, tt := range []struct {
+			level    zapcore.Level
+			expected string
+		}{
+			{zapcore.InfoLevel, "INFO   lob law\n"},
+			{zapcore.ErrorLevel, "ERROR  lob law\n"},
+		} {
+			enc := NewTextEncoder(cfg)
+			buf, err := enc.EncodeEntry(zapcore.Entry{Level: tt.level, Message: "lob law"}, nil)
+			if assert.NoError(t, err, "Unexpected text encoding error.") {
+				assert.Equal(t, tt.expected, buf.String(), "Expected no extra padding for padded levels.")
+			}
+			buf.Free()
+		}
+	})
+}
+
+func TestPaddedNameEncoder(t *testing.T) {
+	tests := []struct {
+		desc     string
+		name     string
+		truncate bool
+		expected string
+	}{
+		{"empty", "", true, "      "},
+		{"shorter", "db", true, "db    "},
+		{"equal", "server", true, "server"},
+		{"longer", "database", true, "datab…"},
+		{"longer multibyte", "données.sql", true, "donné…"},
+		{"longer kept", "database", false, "database"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			arr := &sliceArrayEncoder{}
+			PaddedNameEncoder(6, tt.truncate)(tt.name, arr)
+			assert.Equal(t, []interface{}{tt.expected}, arr.elems, "Unexpected padded name.")
+		})
+	}
+
+	t.Run("in EncoderConfig", func(t *testing.T) {
+		cfg := zapcore.EncoderConfig{MessageKey: "M", NameKey: "N", EncodeName: PaddedNameEncoder(6, true)}
+		enc := NewTextEncoder(cfg)
+		buf, err := enc.EncodeEntry(zapcore.Entry{LoggerName: "db", Message: "lob law"}, nil)
+		if assert.NoError(t, err, "Unexpected text encoding error.") {
+			assert.Equal(t, "db      lob law\n", buf.String())
+		}
+		buf.Free()
+	})
+}
+
+func TestEpochTimeEncoders(t *testing.T) {
+	tests := []struct {
+		desc     string
+		encoder  zapcore.TimeEncoder
+		t        time.Time
+		expected float64
+		text     string
+	}{
+		{"millis", EpochMillisTimeEncoder, time.Unix(1, 500000000), 1500, "1500"},
+		{"millis fraction", EpochMillisTimeEncoder, time.Unix(1, 500500000), 1500.5, "1500.5"},
+		{"millis sub", EpochMillisTimeEncoder, time.Unix(0, 1500), 0.0015, "0.0015"},
+		{"micros", EpochMicrosTimeEncoder, time.Unix(1, 500000000), 1500000, "1500000"},
+		{"micros sub", EpochMicrosTimeEncoder, time.Unix(1, 500), 1000000.5, "1000000.5"},
+		{"micros recent", EpochMicrosTimeEncoder, time.Unix(1529426022, 123456000), 1529426022123456, "1529426022123456"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			arr := &sliceArrayEncoder{}
+			tt.encoder(tt.t, arr)
+			assert.Equal(t, []interface{}{tt.expected}, arr.elems, "Unexpected epoch time.")
+
+			cfg := zapcore.EncoderConfig{MessageKey: "M", TimeKey: "T", EncodeTime: tt.encoder}
+			enc := NewTextEncoder(cfg)
+			buf, err := enc.EncodeEntry(zapcore.Entry{Time: tt.t, Message: "lob law"}, nil)
+			if assert.NoError(t, err, "Unexpected text encoding error.") {
+				assert.Equal(t, tt.text+"  lob law\n", buf.String())
+			}
+			buf.Free()
+		})
+	}
+}
+
+func TestRFC3339TimeEncoders(t *testing.T) {
+	utc := time.Date(2018, 6, 19, 16, 33, 42, 123456789, time.UTC)
+	local := utc.In(time.FixedZone("UTC+2", 2*60*60))
+	tests := []struct {
+		desc     string
+		encoder  zapcore.TimeEncoder
+		t        time.Time
+		expected string
+	}{
+		{"seconds", RFC3339TimeEncoder, utc, "2018-06-19T16:33:42Z"},
+		{"seconds offset", RFC3339TimeEncoder, local, "2018-06-19T18:33:42+02:00"},
+		{"nanos", RFC3339NanoTimeEncoder, utc, "2018-06-19T16:33:42.123456789Z"},
+		{"nanos offset", RFC3339NanoTimeEncoder, local, "2018-06-19T18:33:42.123456789+02:00"},
+		{"millis", RFC3339PrecisionTimeEncoder(time.Millisecond), utc, "2018-06-19T16:33:42.123Z"},
+		{"millis offset", RFC3339PrecisionTimeEncoder(time.Millisecond), local, "2018-06-19T18:33:42.123+02:00"},
+		{"micros", RFC3339PrecisionTimeEncoder(time.Microsecond), utc, "2018-06-19T16:33:42.123456Z"},
+		{"nanos precision", RFC3339PrecisionTimeEncoder(time.Nanosecond), utc, "2018-06-19T16:33:42.123456789Z"},
+		{"zero precision", RFC3339PrecisionTimeEncoder(0), utc, "2018-06-19T16:33:42.123456789Z"},
+		{"second precision", RFC3339PrecisionTimeEncoder(time.Second), utc, "2018-06-19T16:33:42Z"},
+		{"fixed digits", RFC3339PrecisionTimeEncoder(time.Millisecond), utc.Truncate(time.Second), "2018-06-19T16:33:42.000Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			arr := &sliceArrayEncoder{}
+			tt.encoder(tt.t, arr)
+			assert.Equal(t, []interface{}{tt.expected}, arr.elems, "Unexpected RFC 3339 time.")
+		})
+	}
+
+	t.Run("matches time.Truncate", func(t *testing.T) {
+		arr := &sliceArrayEncoder{}
+		ts := time.Date(2018, 6, 19, 16, 33, 42, 999999999, time.UTC)
+		RFC3339PrecisionTimeEncoder(time.Millisecond)(ts, arr)
+		parsed, err := time.Parse(time.RFC3339Nano, arr.elems[0].(string))
+		if assert.NoError(t, err, "Expected valid RFC 3339.") {
+			assert.True(t, ts.Truncate(time.Millisecond).Equal(parsed), "Expected truncation, got %v.", parsed)
+		}
+	})
+}
+
+func TestSyslogTimeEncoder(t *testing.T) {
+	tests := []struct {
+		t        time.Time
+		expected string
+	}{
+		{time.Date(2018, 1, 1, 15, 4, 5, 0, time.UTC), "Jan  1 15:04:05"},
+		{time.Date(2018, 12, 31, 15, 4, 5, 999, time.UTC), "Dec 31 15:04:05"},
+		{time.Date(2018, 6, 9, 3, 0, 0, 0, time.UTC), "Jun  9 03:00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			arr := &sliceArrayEncoder{}
+			SyslogTimeEncoder(tt.t, arr)
+			assert.Equal(t, []interface{}{tt.expected}, arr.elems, "Unexpected syslog time.")
+		})
+	}
+
+	t.Run("in EncoderConfig", func(t *testing.T) {
+		cfg := zapcore.EncoderConfig{MessageKey: "M", TimeKey: "T", EncodeTime: SyslogTimeEncoder}
+		enc := NewTextEncoder(cfg)
+		buf, err := enc.EncodeEntry(zapcore.Entry{Time: tests[0].t, Message: "lob law"}, nil)
+		if assert.NoError(t, err, "Unexpected text encoding error.") {
+			assert.Equal(t, "Jan  1 15:04:05  lob law\n", buf.String())
+		}
+		buf.Free()
+	})
+}
@@ -0,0 +1,75 @@
+package zaptextencoder
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// WithErrorTypes adds a "<key>_type" field holding the dynamic type of each
+// error field of an entry, such as error_type="*fs.PathError".
+func WithErrorTypes(enabled bool) Option {
+	return func(enc *textEncoder) {
+		enc.errorTypes = enabled
+	}
+}
+
+// WithErrorCauseDepth adds a "<key>_cause" field for each of the first n
+// errors wrapped by an error field of an entry, as returned by errors.Unwrap.
+// Zero, the default, disables them.
+func WithErrorCauseDepth(n int) Option {
+	return func(enc *textEncoder) {
+		enc.errorCauseDepth = n
+	}
+}
+
+// WithUnwrapErrors adds a "<key>_chain" array to each error field of an
+// entry, holding the message of the error and of every error it wraps, such
+// as error_chain=["outer: root","root"]. See WithMaxErrorChainDepth.
+func WithUnwrapErrors(enabled bool) Option {
+	return func(enc *textEncoder) {
+		enc.unwrapErrors = enabled
+	}
+}
+
+// WithMaxErrorChainDepth caps the number of errors in a chain added by
+// WithUnwrapErrors, guarding against cyclic or runaway chains. The default
+// is 10.
+func WithMaxErrorChainDepth(n int) Option {
+	return func(enc *textEncoder) {
+		if n > 0 {
+			enc.maxErrorChainDepth = n
+		}
+	}
+}
+
+// addErrorField encodes a zapcore.ErrorType field of an entry, followed by
+// the fields selected by WithErrorTypes, WithErrorCauseDepth and
+// WithUnwrapErrors. Errors added with Logger.With don't go through here, as
+// zap adds them to the encoder directly.
+func (enc *textEncoder) addErrorField(f zapcore.Field) {
+	f.AddTo(enc)
+	err, ok := f.Interface.(error)
+	if !ok {
+		return
+	}
+	if enc.errorTypes {
+		enc.AddString(f.Key+"_type", fmt.Sprintf("%T", err))
+	}
+	cause := err
+	for i := 0; i < enc.errorCauseDepth; i++ {
+		if cause = errors.Unwrap(cause); cause == nil {
+			break
+		}
+		enc.AddString(f.Key+"_cause", cause.Error())
+	}
+	if enc.unwrapErrors {
+		enc.AddArray(f.Key+"_chain", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for i, e := 0, err; i < enc.maxErrorChainDepth && e != nil; i, e = i+1, errors.Unwrap(e) {
+				arr.AppendString(e.Error())
+			}
+			return nil
+		}))
+	}
+}
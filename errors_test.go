@@ -0,0 +1,144 @@
+package zaptextencoder
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+type codeError struct {
+	code int
+}
+
+func (e *codeError) Error() string { return fmt.Sprintf("code %d", e.code) }
+
+func TestErrorFields(t *testing.T) {
+	root := &codeError{code: 42}
+	middle := fmt.Errorf("middle: %w", root)
+	outer := fmt.Errorf("outer: %w", middle)
+
+	tests := []struct {
+		desc     string
+		opts     []Option
+		err      error
+		expected string
+	}{
+		{
+			desc:     "default",
+			err:      outer,
+			expected: "error  lob law  error=\"outer: middle: code 42\"\n",
+		},
+		{
+			desc:     "error types",
+			opts:     []Option{WithErrorTypes(true)},
+			err:      root,
+			expected: "error  lob law  error=\"code 42\"  error_type=\"*zaptextencoder.codeError\"\n",
+		},
+		{
+			desc: "causes",
+			opts: []Option{WithErrorCauseDepth(5)},
+			err:  outer,
+			expected: "error  lob law  error=\"outer: middle: code 42\"" +
+				"  error_cause=\"middle: code 42\"  error_cause=\"code 42\"\n",
+		},
+		{
+			desc:     "cause depth",
+			opts:     []Option{WithErrorCauseDepth(1)},
+			err:      outer,
+			expected: "error  lob law  error=\"outer: middle: code 42\"  error_cause=\"middle: code 42\"\n",
+		},
+		{
+			desc: "types and causes",
+			opts: []Option{WithErrorTypes(true), WithErrorCauseDepth(5)},
+			err:  middle,
+			expected: "error  lob law  error=\"middle: code 42\"  error_type=\"*fmt.wrapError\"" +
+				"  error_cause=\"code 42\"\n",
+		},
+		{
+			desc:     "unwrapped error",
+			opts:     []Option{WithErrorTypes(true), WithErrorCauseDepth(5)},
+			err:      errors.New("boom"),
+			expected: "error  lob law  error=\"boom\"  error_type=\"*errors.errorString\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assertEncodedEntry(t, tt.expected, tt.opts, zap.Error(tt.err))
+		})
+	}
+
+	t.Run("named error", func(t *testing.T) {
+		assertEncodedEntry(t, "error  lob law  cause=\"code 42\"  cause_type=\"*zaptextencoder.codeError\"\n",
+			[]Option{WithErrorTypes(true)}, zap.NamedError("cause", root))
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		assertEncodedEntry(t, "error  lob law\n", []Option{WithErrorTypes(true)}, zap.Error(nil))
+	})
+}
+
+// loopError wraps itself, making for an endless chain.
+type loopError struct{}
+
+func (e *loopError) Error() string { return "loop" }
+func (e *loopError) Unwrap() error { return e }
+
+func TestUnwrapErrors(t *testing.T) {
+	root := errors.New("root")
+	middle := fmt.Errorf("middle: %w", root)
+	outer := fmt.Errorf("outer: %w", middle)
+
+	tests := []struct {
+		desc     string
+		opts     []Option
+		err      error
+		expected string
+	}{
+		{
+			desc: "three levels",
+			opts: []Option{WithUnwrapErrors(true)},
+			err:  outer,
+			expected: "error  lob law  error=\"outer: middle: root\"" +
+				"  error_chain=[\"outer: middle: root\",\"middle: root\",\"root\"]\n",
+		},
+		{
+			desc:     "single error",
+			opts:     []Option{WithUnwrapErrors(true)},
+			err:      root,
+			expected: "error  lob law  error=\"root\"  error_chain=[\"root\"]\n",
+		},
+		{
+			desc:     "capped chain",
+			opts:     []Option{WithUnwrapErrors(true), WithMaxErrorChainDepth(2)},
+			err:      outer,
+			expected: "error  lob law  error=\"outer: middle: root\"  error_chain=[\"outer: middle: root\",\"middle: root\"]\n",
+		},
+		{
+			desc:     "disabled",
+			opts:     []Option{WithUnwrapErrors(false)},
+			err:      outer,
+			expected: "error  lob law  error=\"outer: middle: root\"\n",
+		},
+		{
+			desc:     "auto quoting",
+			opts:     []Option{WithUnwrapErrors(true), WithQuoteMode(QuoteModeAuto)},
+			err:      fmt.Errorf("wrapped:%w", root),
+			expected: "error  lob law  error=wrapped:root  error_chain=[wrapped:root,root]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assertEncodedEntry(t, tt.expected, tt.opts, zap.Error(tt.err))
+		})
+	}
+
+	t.Run("endless chain", func(t *testing.T) {
+		assertEncodedEntry(t,
+			"error  lob law  error=\"loop\"  error_chain=[\"loop\",\"loop\",\"loop\",\"loop\",\"loop\",\"loop\",\"loop\",\"loop\",\"loop\",\"loop\"]\n",
+			[]Option{WithUnwrapErrors(true)}, zap.Error(&loopError{}))
+	})
+}
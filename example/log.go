@@ -1,24 +1,103 @@
 package main
 
 import (
+	"io"
+	"net/http"
 	"os"
-	"zaptextencoder"
+	"sync"
 
+	"github.com/hms58/zaptextencoder"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 type Config struct {
-	Level         zapcore.Level
-	ColorfulLevel bool
+	Level           zapcore.Level
+	ColorfulLevel   bool
+	TrueColorLevel  bool
+	ColorfulMessage bool
+	ColorfulTime    bool
+	HumanDuration   bool
+	// NameWidth pads logger names to a column of that many characters,
+	// cutting longer ones. Zero leaves them as is.
+	NameWidth int
+	// Outputs are the writers entries go to, stdout at any level if there
+	// are none. Level applies to all of them, on top of their own.
+	Outputs []OutputSpec
+	// RingBufferSize keeps that many of the last entries in memory besides
+	// writing them to the outputs, see RingBuffer. Zero keeps none.
+	RingBufferSize int
+
+	// level is the level of the core built by New, see SetLevel.
+	level      zap.AtomicLevel
+	ringBuffer *zaptextencoder.RingBuffer
+}
+
+// OutputSpec is a writer taking the entries at Level or above, encoded
+// with the options of the Config and Options. Colors are only written to
+// terminals.
+type OutputSpec struct {
+	Writer  io.Writer
+	Level   zapcore.Level
+	Options []zaptextencoder.Option
 }
 
+// mu guards the loggers, which AddGlobalFields replaces.
+var mu sync.RWMutex
 var logger *zap.Logger
 var sugaredLogger *zap.SugaredLogger
 var _sugaredLogger *zap.SugaredLogger
 
+// sugar returns the logger of the package-level functions, which skips
+// their frame when adding the caller.
+func sugar() *zap.SugaredLogger {
+	mu.RLock()
+	defer mu.RUnlock()
+	return _sugaredLogger
+}
+
 // New 构造Logger对象
 func New(cfg *Config) error {
+	outputs := cfg.Outputs
+	if len(outputs) == 0 {
+		outputs = []OutputSpec{{Writer: os.Stdout, Level: zapcore.DebugLevel}}
+	}
+	cfg.level = zap.NewAtomicLevelAt(cfg.Level)
+	cores := make([]zapcore.Core, 0, len(outputs))
+	for _, out := range outputs {
+		out := out
+		enabled := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l >= out.Level && cfg.level.Enabled(l)
+		})
+		ws, ok := out.Writer.(zapcore.WriteSyncer)
+		if !ok {
+			ws = zapcore.AddSync(out.Writer)
+		}
+		cores = append(cores, zapcore.NewCore(cfg.newEncoder(out), zapcore.Lock(ws), enabled))
+	}
+	cfg.ringBuffer = nil
+	if cfg.RingBufferSize > 0 {
+		ws, rb := zaptextencoder.RingBufferSink(cfg.RingBufferSize)
+		cores = append(cores, zapcore.NewCore(cfg.newEncoder(OutputSpec{Writer: ws}), ws, cfg.level))
+		cfg.ringBuffer = rb
+	}
+	core := zapcore.NewTee(cores...)
+	pid := zap.Fields(zap.Int("pid", os.Getpid()))
+	caller := zap.AddCaller()
+	callerSkip := zap.AddCallerSkip(1)
+	stacktrace := zap.AddStacktrace(zapcore.ErrorLevel)
+
+	mu.Lock()
+	defer mu.Unlock()
+	logger = zap.New(core, pid, caller, stacktrace)
+	sugaredLogger = logger.Sugar()
+	_sugaredLogger = zap.New(core, pid, caller, callerSkip, stacktrace).Sugar()
+	//zap.ReplaceGlobals(logger)
+	return nil
+}
+
+// newEncoder returns the encoder of out.
+func (cfg *Config) newEncoder(out OutputSpec) zapcore.Encoder {
 	encoderCfg := zapcore.EncoderConfig{
 		MessageKey:     "message",
 		LevelKey:       "level",
@@ -31,110 +110,147 @@ func New(cfg *Config) error {
 		EncodeDuration: zapcore.SecondsDurationEncoder,
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
-	if cfg.ColorfulLevel {
+	if cfg.HumanDuration {
+		encoderCfg.EncodeDuration = zaptextencoder.HumanDurationEncoder
+	}
+	if cfg.NameWidth > 0 {
+		encoderCfg.EncodeName = zaptextencoder.PaddedNameEncoder(cfg.NameWidth, true)
+	}
+	colorful := zaptextencoder.ColorEnabled(out.Writer)
+	if colorful && (cfg.ColorfulLevel || cfg.ColorfulMessage || cfg.ColorfulTime) && out.Writer == os.Stdout && zaptextencoder.IsTerminal(os.Stdout) {
+		// Consoles on Windows only render colors once asked to.
+		if err := zaptextencoder.EnableWindowsVT(); err != nil {
+			colorful = false
+		}
+	}
+	if cfg.ColorfulLevel && colorful {
 		encoderCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		if cfg.TrueColorLevel {
+			encoderCfg.EncodeLevel = zaptextencoder.TrueColorLevelEncoder(zaptextencoder.DefaultTrueColorScheme)
+		}
 	}
-	encoder := zaptextencoder.NewTextEncoder(encoderCfg)
-	//encoder := zapcore.NewConsoleEncoder(encoderCfg)
-	//encoder := zapcore.NewJSONEncoder(encoderCfg)
-
-	cores := []zapcore.Core{
-		zapcore.NewCore(encoder, zapcore.Lock(os.Stdout), cfg.Level),
+	var opts []zaptextencoder.Option
+	if cfg.ColorfulMessage && colorful {
+		opts = append(opts, zaptextencoder.WithMessageColors(zaptextencoder.DefaultColorScheme))
 	}
-	core := zapcore.NewTee(cores...)
-	pid := zap.Fields(zap.Int("pid", os.Getpid()))
-	caller := zap.AddCaller()
-	callerSkip := zap.AddCallerSkip(1)
-	stacktrace := zap.AddStacktrace(zapcore.ErrorLevel)
+	if cfg.ColorfulTime && colorful {
+		opts = append(opts, zaptextencoder.WithTimeColor(zaptextencoder.ColorDimGray))
+	}
+	return zaptextencoder.NewTextEncoder(encoderCfg, append(opts, out.Options...)...)
+	//return zapcore.NewConsoleEncoder(encoderCfg)
+	//return zapcore.NewJSONEncoder(encoderCfg)
+}
 
-	logger = zap.New(core, pid, caller, stacktrace)
+// SetLevel changes the level of the loggers built by New while they're in
+// use. New must have been called with cfg.
+func (cfg *Config) SetLevel(level zapcore.Level) {
+	cfg.level.SetLevel(level)
+}
+
+// RingBuffer returns the last entries logged by the loggers built by New,
+// or nil if RingBufferSize is zero.
+func (cfg *Config) RingBuffer() *zaptextencoder.RingBuffer {
+	return cfg.ringBuffer
+}
+
+// LevelHandler serves the level of the loggers built by New. GET requests
+// get it as JSON, {"level":"info"}, and PUT requests change it with the same
+// body, see zap.AtomicLevel.ServeHTTP.
+func (cfg *Config) LevelHandler() http.Handler {
+	return cfg.level
+}
+
+// AddGlobalFields adds fields to every entry logged after the call, by the
+// package-level functions or the loggers. New must have been called.
+func (cfg *Config) AddGlobalFields(fields ...zap.Field) {
+	mu.Lock()
+	defer mu.Unlock()
+	logger = logger.With(fields...)
 	sugaredLogger = logger.Sugar()
-	_sugaredLogger = zap.New(core, pid, caller, callerSkip, stacktrace).Sugar()
-	//zap.ReplaceGlobals(logger)
-	return nil
+	_sugaredLogger = _sugaredLogger.Desugar().With(fields...).Sugar()
 }
 
 // Debug logger
 func Debug(args ...interface{}) {
-	_sugaredLogger.Debug(args...)
+	sugar().Debug(args...)
 }
 
 // Info logger
 func Info(args ...interface{}) {
-	_sugaredLogger.Info(args...)
+	sugar().Info(args...)
 }
 
 // Warn logger
 func Warn(args ...interface{}) {
-	_sugaredLogger.Warn(args...)
+	sugar().Warn(args...)
 }
 
 // Error logger
 func Error(args ...interface{}) {
-	_sugaredLogger.Error(args...)
+	sugar().Error(args...)
 }
 
 // Fatal logger
 func Fatal(args ...interface{}) {
-	_sugaredLogger.Fatal(args...)
+	sugar().Fatal(args...)
 }
 
 // Debugf logger
 func Debugf(template string, args ...interface{}) {
-	_sugaredLogger.Debugf(template, args...)
+	sugar().Debugf(template, args...)
 }
 
 // Infof logger
 func Infof(template string, args ...interface{}) {
-	_sugaredLogger.Infof(template, args...)
+	sugar().Infof(template, args...)
 }
 
 // Warnf logger
 func Warnf(template string, args ...interface{}) {
-	_sugaredLogger.Warnf(template, args...)
+	sugar().Warnf(template, args...)
 }
 
 // Errorf logger
 func Errorf(template string, args ...interface{}) {
-	_sugaredLogger.Errorf(template, args...)
+	sugar().Errorf(template, args...)
 }
 
 // Fatalf logger
 func Fatalf(template string, args ...interface{}) {
-	_sugaredLogger.Fatalf(template, args...)
+	sugar().Fatalf(template, args...)
 }
 
 // Panicf logger
 func Panicf(template string, args ...interface{}) {
-	_sugaredLogger.Panicf(template, args...)
+	sugar().Panicf(template, args...)
 }
 
 // Debugw logger
 func Debugw(msg string, keysAndValues ...interface{}) {
-	_sugaredLogger.Debugw(msg, keysAndValues...)
+	sugar().Debugw(msg, keysAndValues...)
 }
 
 // Infow logger
 func Infow(msg string, keysAndValues ...interface{}) {
-	_sugaredLogger.Infow(msg, keysAndValues...)
+	sugar().Infow(msg, keysAndValues...)
 }
 
 // Warnw logger
 func Warnw(msg string, keysAndValues ...interface{}) {
-	_sugaredLogger.Warnw(msg, keysAndValues...)
+	sugar().Warnw(msg, keysAndValues...)
 }
 
 // Errorw logger
 func Errorw(msg string, keysAndValues ...interface{}) {
-	_sugaredLogger.Errorw(msg, keysAndValues...)
+	sugar().Errorw(msg, keysAndValues...)
 }
 
 // Fatalw logger
 func Fatalw(msg string, keysAndValues ...interface{}) {
-	_sugaredLogger.Fatalw(msg, keysAndValues...)
+	sugar().Fatalw(msg, keysAndValues...)
 }
 
 // Panicw logger
 func Panicw(msg string, keysAndValues ...interface{}) {
-	_sugaredLogger.Panicw(msg, keysAndValues...)
+	sugar().Panicw(msg, keysAndValues...)
 }
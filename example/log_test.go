@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hms58/zaptextencoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestConfigSetLevel(t *testing.T) {
+	cfg := &Config{Level: zapcore.InfoLevel}
+	require.NoError(t, New(cfg), "Unexpected error building the logger.")
+	assert.Nil(t, logger.Check(zapcore.DebugLevel, "test"), "Debug entries should be dropped at info level.")
+
+	cfg.SetLevel(zapcore.FatalLevel)
+	assert.Nil(t, logger.Check(zapcore.ErrorLevel, "test"), "Error entries should be dropped at fatal level.")
+	assert.False(t, _sugaredLogger.Desugar().Core().Enabled(zapcore.ErrorLevel), "The sugared logger should share the level.")
+
+	cfg.SetLevel(zapcore.DebugLevel)
+	assert.NotNil(t, logger.Check(zapcore.DebugLevel, "test"), "Debug entries should be written at debug level.")
+	assert.True(t, _sugaredLogger.Desugar().Core().Enabled(zapcore.DebugLevel), "The sugared logger should share the level.")
+}
+
+func TestConfigLevelHandler(t *testing.T) {
+	cfg := &Config{Level: zapcore.InfoLevel}
+	require.NoError(t, New(cfg), "Unexpected error building the logger.")
+	srv := httptest.NewServer(cfg.LevelHandler())
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL)
+	require.NoError(t, err, "Unexpected error getting the level.")
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err, "Unexpected error reading the level.")
+	assert.Equal(t, http.StatusOK, res.StatusCode, "Unexpected status getting the level.")
+	assert.JSONEq(t, `{"level":"info"}`, string(body), "Unexpected level.")
+	assert.NotNil(t, logger.Check(zapcore.InfoLevel, "test"), "Info entries should be written at info level.")
+
+	req, err := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader(`{"level":"error"}`))
+	require.NoError(t, err, "Unexpected error building the request.")
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err, "Unexpected error setting the level.")
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode, "Unexpected status setting the level.")
+	assert.Nil(t, logger.Check(zapcore.DebugLevel, "test"), "Debug entries should be dropped at error level.")
+	assert.Nil(t, logger.Check(zapcore.InfoLevel, "test"), "Info entries should be dropped at error level.")
+	assert.NotNil(t, logger.Check(zapcore.ErrorLevel, "test"), "Error entries should be written at error level.")
+}
+
+func TestConfigAddGlobalFields(t *testing.T) {
+	f, err := ioutil.TempFile(t.TempDir(), "stdout")
+	require.NoError(t, err, "Unexpected error creating the output file.")
+	defer f.Close()
+	stdout := os.Stdout
+	os.Stdout = f
+	defer func() { os.Stdout = stdout }()
+
+	cfg := &Config{Level: zapcore.InfoLevel}
+	require.NoError(t, New(cfg), "Unexpected error building the logger.")
+	Info("before")
+	cfg.AddGlobalFields(zap.String("shard", "3"))
+	Info("after")
+	logger.Info("after")
+	sugaredLogger.Info("after")
+
+	out, err := ioutil.ReadFile(f.Name())
+	require.NoError(t, err, "Unexpected error reading the output.")
+	lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	require.Len(t, lines, 4, "Unexpected output %q.", out)
+	assert.NotContains(t, lines[0], "shard=", "Fields mustn't be added to earlier entries.")
+	for _, line := range lines[1:] {
+		assert.Contains(t, line, `shard="3"`, "Expected the global field.")
+		assert.Contains(t, line, "log_test.go:", "Expected the caller of the logger.")
+	}
+}
+
+func TestConfigOutputs(t *testing.T) {
+	var errors bytes.Buffer
+	cfg := &Config{
+		Level: zapcore.DebugLevel,
+		Outputs: []OutputSpec{
+			{Writer: os.Stdout, Level: zapcore.InfoLevel},
+			{Writer: &errors, Level: zapcore.ErrorLevel, Options: []zaptextencoder.Option{zaptextencoder.WithFieldSeparator(" ")}},
+		},
+	}
+	require.NoError(t, New(cfg), "Unexpected error building the logger.")
+	assert.Nil(t, logger.Check(zapcore.DebugLevel, "test"), "Debug entries shouldn't go to any output.")
+
+	logger.Info("info entry")
+	logger.Error("error entry")
+	assert.NotContains(t, errors.String(), "info entry", "Expected only the error entry.")
+	// The output's own options apply.
+	assert.Contains(t, errors.String(), " ERROR ", "Expected the error entry.")
+	assert.Contains(t, errors.String(), " error entry\n", "Expected the error entry.")
+
+	// Level still applies to every output.
+	cfg.SetLevel(zapcore.FatalLevel)
+	logger.Error("dropped")
+	assert.NotContains(t, errors.String(), "dropped", "Expected Level to apply to every output.")
+}
+
+func TestConfigRingBuffer(t *testing.T) {
+	const size = 4
+	cfg := &Config{
+		Level:          zapcore.DebugLevel,
+		Outputs:        []OutputSpec{{Writer: ioutil.Discard, Level: zapcore.ErrorLevel}},
+		RingBufferSize: size,
+	}
+	require.NoError(t, New(cfg), "Unexpected error building the logger.")
+	for i := 0; i < size+5; i++ {
+		logger.Debug("entry", zap.Int("i", i))
+	}
+
+	entries := cfg.RingBuffer().Entries()
+	require.Len(t, entries, size, "Expected the last entries only.")
+	for i, entry := range entries {
+		assert.Contains(t, entry, fmt.Sprintf("entry  i=%d\n", i+5), "Unexpected entry.")
+	}
+
+	cfg = &Config{}
+	require.NoError(t, New(cfg), "Unexpected error building the logger.")
+	assert.Nil(t, cfg.RingBuffer(), "Expected no ring buffer by default.")
+}
@@ -1,21 +1,39 @@
 package main
 
 import (
+	"flag"
 	"log"
+	"net/http"
 	"os"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 func main() {
+	addr := flag.String("addr", "", "serve the log level at /log/level on this address")
+	flag.Parse()
+
 	cfg := &Config{
-		Level:         zapcore.DebugLevel,
-		ColorfulLevel: true,
+		Level:           zapcore.DebugLevel,
+		ColorfulLevel:   true,
+		ColorfulMessage: true,
+		ColorfulTime:    true,
+		HumanDuration:   true,
+		NameWidth:       8,
 	}
 	if err := New(cfg); err != nil {
 		log.Fatal(err)
 	}
+	if *addr != "" {
+		http.Handle("/log/level", cfg.LevelHandler())
+		go func() {
+			if err := http.ListenAndServe(*addr, nil); err != nil {
+				Errorf("serving the log level: %v", err)
+			}
+		}()
+	}
 	a := []int{1, 1}
 	s := struct {
 		Key string
@@ -32,7 +50,10 @@ func main() {
 	logger.Info("test", zap.String("key", "string\n"))
 	logger.Info("test", zap.Int("key", 1))
 	logger.Info("test", zap.Any("key", a))
+	logger.Info("test", zap.Duration("elapsed", 1500*time.Millisecond))
 	logger.With(zap.String("module", "testmod")).Info("test", zap.String("key", "string"))
+	logger.Named("db").Info("test", zap.String("key", "string"))
+	logger.Named("scheduler").Info("test", zap.String("key", "string"))
 	sugaredLogger.Info("test", "string")
 	Debug("test", "string")
 	Errorf("error: %v", os.ErrNotExist)
@@ -0,0 +1,31 @@
+package zaptextencoder
+
+import "go.uber.org/zap/zapcore"
+
+// FieldPredicate reports whether a field added to a FilteringEncoder
+// should be kept. key is the key of field, or that of its array for the
+// elements of arrays.
+type FieldPredicate func(key string, field zapcore.Field) bool
+
+// FilteringEncoder is a zapcore.Encoder dropping the fields, added by
+// logger.With or a logging call, that a FieldPredicate rejects. The fields
+// of objects and the elements of arrays are filtered too.
+type FilteringEncoder struct {
+	*interceptingEncoder
+}
+
+// WrapWithFilter returns a FilteringEncoder dropping the fields added to
+// enc that pred rejects, or enc itself if pred is nil.
+func WrapWithFilter(enc zapcore.Encoder, pred FieldPredicate) zapcore.Encoder {
+	if pred == nil {
+		return enc
+	}
+	return &FilteringEncoder{newInterceptingEncoder(enc, func(f zapcore.Field) (zapcore.Field, bool) {
+		return f, pred(f.Key, f)
+	})}
+}
+
+// Clone returns a FilteringEncoder wrapping a clone of the encoder.
+func (e *FilteringEncoder) Clone() zapcore.Encoder {
+	return &FilteringEncoder{e.clone()}
+}
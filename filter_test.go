@@ -0,0 +1,45 @@
+package zaptextencoder
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWrapWithFilter(t *testing.T) {
+	noDebug := func(key string, field zapcore.Field) bool {
+		return !strings.HasPrefix(key, "debug_")
+	}
+	var out bytes.Buffer
+	enc := WrapWithFilter(NewTextEncoder(_optionsEncoderConfig), noDebug)
+	logger := zap.New(zapcore.NewCore(enc, zapcore.AddSync(&out), zap.DebugLevel))
+
+	logger.With(zap.String("debug_ctx", "x"), zap.String("ctx", "y")).Info("lob law",
+		zap.Int("debug_n", 1),
+		zap.Int("n", 2),
+		zap.Object("obj", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("debug_inner", "x")
+			enc.AddString("inner", "y")
+			return nil
+		})),
+		zap.Ints("debug_ints", []int{1, 2}),
+		zap.Ints("ints", []int{3, 4}),
+	)
+	assert.Equal(t, "info   ctx=\"y\"  lob law  n=2  obj={inner=\"y\"}  ints=[3,4]\n", out.String(), "Unexpected filtered output.")
+
+	out.Reset()
+	dropOdd := func(key string, field zapcore.Field) bool {
+		return field.Type != zapcore.Int64Type || field.Integer%2 == 0
+	}
+	logger = zap.New(zapcore.NewCore(WrapWithFilter(NewTextEncoder(_optionsEncoderConfig), dropOdd), zapcore.AddSync(&out), zap.DebugLevel))
+	logger.Info("lob law", zap.Ints("ints", []int{1, 2, 3, 4}), zap.Int("n", 5))
+	assert.Equal(t, "info   lob law  ints=[2,4]\n", out.String(), "Expected array elements to be filtered.")
+
+	assert.IsType(t, &FilteringEncoder{}, enc.Clone(), "Expected clones to filter fields too.")
+	plain := NewTextEncoder(_optionsEncoderConfig)
+	assert.Equal(t, plain, WrapWithFilter(plain, nil), "Expected no wrapper without a predicate.")
+}
@@ -0,0 +1,78 @@
+//go:build go1.18
+// +build go1.18
+
+package zaptextencoder
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var _fuzzSeeds = []string{
+	"foo",
+	`"`,
+	`\`,
+	`foo"foo`,
+	"foo\n",
+	"\n\r\t\b\f",
+	"<>&",
+	string(byte(0x07)),
+	"☃",
+	"\xed\xa0\x80",
+	"foo\xed\xa0\x80",
+	"\x00\x01\xfe\xff",
+	"\xf0\x9f\x92",
+	"💩\x80🤔",
+}
+
+// checkEscaped checks that escaped is the valid, JSON-compatible escaping
+// of input, with one U+FFFD for each invalid UTF-8 byte.
+func checkEscaped(t *testing.T, input []byte, escaped string) {
+	if !utf8.ValidString(escaped) {
+		t.Fatalf("Escaping %q gave invalid UTF-8 %q.", input, escaped)
+	}
+	for i := 0; i < len(escaped); i++ {
+		if escaped[i] < 0x20 {
+			t.Fatalf("Escaping %q left control character %#x in %q.", input, escaped[i], escaped)
+		}
+	}
+	var decoded string
+	if err := json.Unmarshal([]byte(`"`+escaped+`"`), &decoded); err != nil {
+		t.Fatalf("Escaping %q gave %q, which isn't a JSON string: %v", input, escaped, err)
+	}
+	var expected strings.Builder
+	for i := 0; i < len(input); {
+		r, size := utf8.DecodeRune(input[i:])
+		expected.WriteRune(r)
+		i += size
+	}
+	if decoded != expected.String() {
+		t.Fatalf("Escaping %q gave %q, which decodes to %q.", input, escaped, decoded)
+	}
+}
+
+func FuzzSafeAddString(f *testing.F) {
+	for _, seed := range _fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		enc := &textEncoder{buf: bufferPool.Get()}
+		defer enc.buf.Free()
+		enc.safeAddString(s)
+		checkEscaped(t, []byte(s), enc.buf.String())
+	})
+}
+
+func FuzzSafeAddByteString(f *testing.F) {
+	for _, seed := range _fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		enc := &textEncoder{buf: bufferPool.Get()}
+		defer enc.buf.Free()
+		enc.safeAddByteString(b)
+		checkEscaped(t, b, enc.buf.String())
+	})
+}
@@ -0,0 +1,193 @@
+package zaptextencoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// gelfEncoder writes entries as GELF 1.1 JSON objects, see NewGELFEncoder.
+// Its fields are encoded by a JSON encoder with prefixed keys, up to the
+// first namespace, whose fields are those of an object.
+type gelfEncoder struct {
+	zapcore.Encoder
+	cfg        *zapcore.EncoderConfig
+	host       string
+	namespaced bool
+}
+
+// NewGELFEncoder creates an encoder writing entries as Graylog Extended Log
+// Format 1.1 JSON objects sent from host, such as
+//
+//	{"version":"1.1","host":"db-1","short_message":"lob law","timestamp":1529426022.000,"level":6,"_answer":42}
+//
+// The level is the syslog severity of the entry: 7 for debug, 6 for info, 4
+// for warn, 3 for error, 2 for dpanic, 1 for panic and 0 for fatal. The
+// stacktrace, if any, is written after the message in full_message. Fields
+// are prefixed with '_', as GELF asks of additional fields, including the
+// logger name and the caller under the keys of cfg. GELF reserves _id, so
+// servers drop fields keyed id. Times and durations are
+// encoded as cfg asks, and entries end with cfg.LineEnding, such as "\x00"
+// for GELF over TCP.
+func NewGELFEncoder(cfg zapcore.EncoderConfig, host string) zapcore.Encoder {
+	return &gelfEncoder{
+		Encoder: zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+			LineEnding:     cfg.LineEnding,
+			EncodeTime:     cfg.EncodeTime,
+			EncodeDuration: cfg.EncodeDuration,
+		}),
+		cfg:  &cfg,
+		host: host,
+	}
+}
+
+// gelfLevels maps zap's levels, from DebugLevel, to syslog severities.
+var gelfLevels = [...]int{7, 6, 4, 3, 2, 1, 0}
+
+func gelfLevel(l zapcore.Level) int {
+	if l < zapcore.DebugLevel {
+		return gelfLevels[0]
+	}
+	if l > zapcore.FatalLevel {
+		return gelfLevels[len(gelfLevels)-1]
+	}
+	return gelfLevels[l-zapcore.DebugLevel]
+}
+
+// key returns key prefixed unless a namespace is open.
+func (enc *gelfEncoder) key(key string) string {
+	if enc.namespaced {
+		return key
+	}
+	return "_" + key
+}
+
+func (enc *gelfEncoder) AddArray(key string, v zapcore.ArrayMarshaler) error {
+	return enc.Encoder.AddArray(enc.key(key), v)
+}
+
+func (enc *gelfEncoder) AddObject(key string, v zapcore.ObjectMarshaler) error {
+	return enc.Encoder.AddObject(enc.key(key), v)
+}
+
+func (enc *gelfEncoder) AddReflected(key string, v interface{}) error {
+	return enc.Encoder.AddReflected(enc.key(key), v)
+}
+
+func (enc *gelfEncoder) OpenNamespace(key string) {
+	enc.Encoder.OpenNamespace(enc.key(key))
+	enc.namespaced = true
+}
+
+func (enc *gelfEncoder) AddBinary(key string, v []byte)          { enc.Encoder.AddBinary(enc.key(key), v) }
+func (enc *gelfEncoder) AddByteString(key string, v []byte)      { enc.Encoder.AddByteString(enc.key(key), v) }
+func (enc *gelfEncoder) AddBool(key string, v bool)              { enc.Encoder.AddBool(enc.key(key), v) }
+func (enc *gelfEncoder) AddComplex128(key string, v complex128)  { enc.Encoder.AddComplex128(enc.key(key), v) }
+func (enc *gelfEncoder) AddComplex64(key string, v complex64)    { enc.Encoder.AddComplex64(enc.key(key), v) }
+func (enc *gelfEncoder) AddDuration(key string, v time.Duration) { enc.Encoder.AddDuration(enc.key(key), v) }
+func (enc *gelfEncoder) AddFloat64(key string, v float64)        { enc.Encoder.AddFloat64(enc.key(key), v) }
+func (enc *gelfEncoder) AddFloat32(key string, v float32)        { enc.Encoder.AddFloat32(enc.key(key), v) }
+func (enc *gelfEncoder) AddInt(key string, v int)                { enc.Encoder.AddInt(enc.key(key), v) }
+func (enc *gelfEncoder) AddInt64(key string, v int64)            { enc.Encoder.AddInt64(enc.key(key), v) }
+func (enc *gelfEncoder) AddInt32(key string, v int32)            { enc.Encoder.AddInt32(enc.key(key), v) }
+func (enc *gelfEncoder) AddInt16(key string, v int16)            { enc.Encoder.AddInt16(enc.key(key), v) }
+func (enc *gelfEncoder) AddInt8(key string, v int8)              { enc.Encoder.AddInt8(enc.key(key), v) }
+func (enc *gelfEncoder) AddString(key, v string)                 { enc.Encoder.AddString(enc.key(key), v) }
+func (enc *gelfEncoder) AddTime(key string, v time.Time)         { enc.Encoder.AddTime(enc.key(key), v) }
+func (enc *gelfEncoder) AddUint(key string, v uint)              { enc.Encoder.AddUint(enc.key(key), v) }
+func (enc *gelfEncoder) AddUint64(key string, v uint64)          { enc.Encoder.AddUint64(enc.key(key), v) }
+func (enc *gelfEncoder) AddUint32(key string, v uint32)          { enc.Encoder.AddUint32(enc.key(key), v) }
+func (enc *gelfEncoder) AddUint16(key string, v uint16)          { enc.Encoder.AddUint16(enc.key(key), v) }
+func (enc *gelfEncoder) AddUint8(key string, v uint8)            { enc.Encoder.AddUint8(enc.key(key), v) }
+func (enc *gelfEncoder) AddUintptr(key string, v uintptr)        { enc.Encoder.AddUintptr(enc.key(key), v) }
+
+func (enc *gelfEncoder) Clone() zapcore.Encoder {
+	clone := *enc
+	clone.Encoder = enc.Encoder.Clone()
+	return &clone
+}
+
+func (enc *gelfEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := bufferPool.Get()
+	final.AppendString(`{"version":"1.1","host":`)
+	appendJSONString(final, enc.host)
+	final.AppendString(`,"short_message":`)
+	appendJSONString(final, ent.Message)
+	if ent.Stack != "" && enc.cfg.StacktraceKey != "" {
+		final.AppendString(`,"full_message":`)
+		appendJSONString(final, ent.Message+"\n"+ent.Stack)
+	}
+	var nanos int64
+	if !ent.Time.IsZero() {
+		nanos = ent.Time.UnixNano()
+	}
+	final.AppendString(`,"timestamp":`)
+	final.AppendString(strconv.FormatFloat(float64(nanos)/float64(time.Second), 'f', 3, 64))
+	final.AppendString(`,"level":`)
+	final.AppendInt(int64(gelfLevel(ent.Level)))
+
+	// The name and caller are written before the fields, as they can't be
+	// added to the JSON encoder, which may be in a namespace.
+	arr := getSliceEncoder()
+	if ent.LoggerName != "" && enc.cfg.NameKey != "" {
+		nameEncoder := enc.cfg.EncodeName
+		if nameEncoder == nil {
+			nameEncoder = zapcore.FullNameEncoder
+		}
+		nameEncoder(ent.LoggerName, arr)
+		enc.appendHeaderField(final, enc.cfg.NameKey, arr)
+	}
+	if ent.Caller.Defined && enc.cfg.CallerKey != "" && enc.cfg.EncodeCaller != nil {
+		enc.cfg.EncodeCaller(ent.Caller, arr)
+		enc.appendHeaderField(final, enc.cfg.CallerKey, arr)
+	}
+	putSliceEncoder(arr)
+
+	prefixed := make([]zapcore.Field, len(fields))
+	namespaced := enc.namespaced
+	for i, f := range fields {
+		if !namespaced {
+			f.Key = "_" + f.Key
+		}
+		namespaced = namespaced || f.Type == zapcore.NamespaceType
+		prefixed[i] = f
+	}
+	body, err := enc.Encoder.EncodeEntry(zapcore.Entry{}, prefixed)
+	if err != nil {
+		bufferPool.Put(final)
+		return nil, err
+	}
+	defer body.Free()
+	// Skip the opening brace of the fields, and keep the closing one and
+	// the line ending.
+	b := body.Bytes()
+	if len(b) > 1 && b[1] != '}' {
+		final.AppendByte(',')
+	}
+	final.Write(b[1:])
+	bufferPool.handOff()
+	return final, nil
+}
+
+// appendHeaderField writes the element encoded in arr as a string field
+// under key, and empties arr.
+func (enc *gelfEncoder) appendHeaderField(buf *buffer.Buffer, key string, arr *sliceArrayEncoder) {
+	if len(arr.elems) == 0 {
+		return
+	}
+	buf.AppendByte(',')
+	appendJSONString(buf, "_"+key)
+	buf.AppendByte(':')
+	appendJSONString(buf, fmt.Sprint(arr.elems[0]))
+	arr.elems = arr.elems[:0]
+}
+
+// appendJSONString writes s quoted as a JSON string.
+func appendJSONString(buf *buffer.Buffer, s string) {
+	b, _ := json.Marshal(s)
+	buf.Write(b)
+}
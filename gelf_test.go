@@ -0,0 +1,116 @@
+package zaptextencoder
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func decodeGELF(t *testing.T, enc zapcore.Encoder, ent zapcore.Entry, fields ...zapcore.Field) map[string]interface{} {
+	buf, err := enc.EncodeEntry(ent, fields)
+	require.NoError(t, err, "Unexpected GELF encoding error.")
+	defer buf.Free()
+	require.True(t, strings.HasSuffix(buf.String(), "}\n"), "Expected an entry per line, got %q.", buf.String())
+
+	var m map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(buf.String()))
+	dec.UseNumber()
+	require.NoError(t, dec.Decode(&m), "Expected valid JSON, got %q.", buf.String())
+	return m
+}
+
+func TestGELFEncoder(t *testing.T) {
+	cfg := zapcore.EncoderConfig{
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		StacktraceKey:  "stacktrace",
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	}
+	enc := NewGELFEncoder(cfg, "db-1")
+	enc.AddString("service", "api")
+	ent := zapcore.Entry{
+		Level:      zapcore.WarnLevel,
+		Time:       time.Unix(1529426022, 123456789),
+		LoggerName: "main",
+		Message:    "lob law",
+		Caller:     zapcore.NewEntryCaller(0, "/src/pkg/main.go", 42, true),
+		Stack:      "main.main\n\tmain.go:42",
+	}
+
+	m := decodeGELF(t, enc, ent,
+		zap.Int("answer", 42),
+		zap.Duration("elapsed", time.Second),
+		zap.Object("obj", event{"start", 1}),
+		zap.Error(errors.New("fail")),
+	)
+	assert.Equal(t, map[string]interface{}{
+		"version":       "1.1",
+		"host":          "db-1",
+		"short_message": "lob law",
+		"full_message":  "lob law\nmain.main\n\tmain.go:42",
+		"timestamp":     json.Number("1529426022.123"),
+		"level":         json.Number("4"),
+		"_logger":       "main",
+		"_caller":       "pkg/main.go:42",
+		"_service":      "api",
+		"_answer":       json.Number("42"),
+		"_elapsed":      "1s",
+		"_obj":          map[string]interface{}{"name": "start", "id": json.Number("1")},
+		"_error":        "fail",
+	}, m, "Unexpected GELF entry.")
+
+	ts, err := m["timestamp"].(json.Number).Float64()
+	require.NoError(t, err, "Expected a float timestamp.")
+	assert.InDelta(t, 1529426022.123, ts, 1e-3, "Unexpected timestamp.")
+}
+
+func TestGELFEncoderMinimal(t *testing.T) {
+	m := decodeGELF(t, NewGELFEncoder(zapcore.EncoderConfig{}, "db-1"), zapcore.Entry{Message: "lob law"})
+	assert.Equal(t, map[string]interface{}{
+		"version":       "1.1",
+		"host":          "db-1",
+		"short_message": "lob law",
+		"timestamp":     json.Number("0.000"),
+		"level":         json.Number("6"),
+	}, m, "Unexpected GELF entry.")
+}
+
+func TestGELFEncoderLevels(t *testing.T) {
+	enc := NewGELFEncoder(zapcore.EncoderConfig{}, "db-1")
+	for level, expected := range map[zapcore.Level]string{
+		zapcore.DebugLevel:  "7",
+		zapcore.InfoLevel:   "6",
+		zapcore.WarnLevel:   "4",
+		zapcore.ErrorLevel:  "3",
+		zapcore.DPanicLevel: "2",
+		zapcore.PanicLevel:  "1",
+		zapcore.FatalLevel:  "0",
+	} {
+		m := decodeGELF(t, enc, zapcore.Entry{Level: level})
+		assert.Equal(t, json.Number(expected), m["level"], "Unexpected severity of %v.", level)
+	}
+}
+
+func TestGELFEncoderClone(t *testing.T) {
+	enc := NewGELFEncoder(zapcore.EncoderConfig{}, "db-1")
+	enc.AddInt("a", 1)
+	clone := enc.Clone()
+	clone.OpenNamespace("ns")
+	clone.AddInt("b", 2)
+
+	m := decodeGELF(t, clone, zapcore.Entry{}, zap.Int("c", 3))
+	assert.Equal(t, json.Number("1"), m["_a"], "Expected the parent's fields.")
+	assert.Equal(t, map[string]interface{}{"b": json.Number("2"), "c": json.Number("3")}, m["_ns"], "Expected fields in the namespace unprefixed.")
+
+	m = decodeGELF(t, enc, zapcore.Entry{}, zap.Namespace("ns"), zap.Int("c", 3))
+	assert.Equal(t, map[string]interface{}{"c": json.Number("3")}, m["_ns"], "Expected entry fields in the namespace unprefixed.")
+	assert.NotContains(t, m, "_b", "Expected the clone's fields to stay in the clone.")
+}
@@ -3,6 +3,12 @@ module github.com/hms58/zaptextencoder
 go 1.16
 
 require (
-	github.com/stretchr/testify v1.4.0
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/stretchr/testify v1.7.0
+	github.com/tinylib/msgp v1.1.6
 	go.uber.org/zap v1.16.0
+	golang.org/x/text v0.3.6
+	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
+	gopkg.in/yaml.v2 v2.3.0 // indirect
+	pgregory.net/rapid v0.4.8
 )
@@ -0,0 +1,82 @@
+package zaptextencoder
+
+import (
+	"compress/gzip"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// GzipWriter is a zapcore.WriteSyncer compressing the entries written to it
+// into a single gzip stream, ended by Close. The compressed data is flushed
+// on Sync, and when enough has been written for a ChunkedGzipWriteSyncer,
+// so that what has been written so far can be decompressed even before the
+// stream ends.
+type GzipWriter struct {
+	mu        sync.Mutex
+	ws        zapcore.WriteSyncer
+	gz        *gzip.Writer
+	chunkSize int
+	// pending is the number of bytes written since the last flush.
+	pending int
+}
+
+// GzipWriteSyncer returns a GzipWriter writing to ws at a compression
+// level of compress/gzip. An invalid level is taken as
+// gzip.DefaultCompression.
+func GzipWriteSyncer(ws zapcore.WriteSyncer, level int) *GzipWriter {
+	gz, err := gzip.NewWriterLevel(ws, level)
+	if err != nil {
+		gz = gzip.NewWriter(ws)
+	}
+	return &GzipWriter{ws: ws, gz: gz}
+}
+
+// ChunkedGzipWriteSyncer returns a GzipWriter writing to ws, which also
+// flushes once chunkSize bytes have been written to it since the last
+// flush. A chunkSize below 1 flushes on Sync only.
+func ChunkedGzipWriteSyncer(ws zapcore.WriteSyncer, chunkSize int) *GzipWriter {
+	w := GzipWriteSyncer(ws, gzip.DefaultCompression)
+	if chunkSize > 0 {
+		w.chunkSize = chunkSize
+	}
+	return w
+}
+
+// Write compresses p.
+func (w *GzipWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n, err := w.gz.Write(p)
+	if err != nil {
+		return n, err
+	}
+	w.pending += n
+	if w.chunkSize > 0 && w.pending >= w.chunkSize {
+		w.pending = 0
+		err = w.gz.Flush()
+	}
+	return n, err
+}
+
+// Sync flushes the compressed data to the underlying writer and syncs it.
+func (w *GzipWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = 0
+	if err := w.gz.Flush(); err != nil {
+		return err
+	}
+	return w.ws.Sync()
+}
+
+// Close ends the gzip stream and syncs the underlying writer, which isn't
+// closed.
+func (w *GzipWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.gz.Close(); err != nil {
+		return err
+	}
+	return w.ws.Sync()
+}
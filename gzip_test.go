@@ -0,0 +1,69 @@
+package zaptextencoder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// gunzip decompresses b, which may be an unfinished stream.
+func gunzip(t *testing.T, b []byte) string {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	require.NoError(t, err, "Unexpected error reading the gzip header.")
+	out, err := ioutil.ReadAll(r)
+	if err != io.ErrUnexpectedEOF {
+		require.NoError(t, err, "Unexpected error decompressing.")
+	}
+	return string(out)
+}
+
+func logLines(logger *zap.Logger, n int) string {
+	var expected strings.Builder
+	for i := 0; i < n; i++ {
+		logger.Info("lob law", zap.Int("i", i))
+		fmt.Fprintf(&expected, "info   lob law  i=%d\n", i)
+	}
+	return expected.String()
+}
+
+func TestGzipWriteSyncer(t *testing.T) {
+	for _, level := range []int{gzip.DefaultCompression, gzip.BestSpeed, gzip.BestCompression, 42} {
+		t.Run(fmt.Sprint(level), func(t *testing.T) {
+			var out bytes.Buffer
+			w := GzipWriteSyncer(zapcore.AddSync(&out), level)
+			logger := zap.New(zapcore.NewCore(NewTextEncoder(_optionsEncoderConfig), w, zap.DebugLevel))
+
+			expected := logLines(logger, 10)
+			require.NoError(t, w.Sync(), "Unexpected error syncing.")
+			assert.Equal(t, expected, gunzip(t, out.Bytes()), "Expected what was written before Sync.")
+
+			expected += logLines(logger, 10)
+			require.NoError(t, w.Close(), "Unexpected error closing.")
+			assert.Equal(t, expected, gunzip(t, out.Bytes()), "Expected every entry after Close.")
+		})
+	}
+}
+
+func TestChunkedGzipWriteSyncer(t *testing.T) {
+	var out bytes.Buffer
+	w := ChunkedGzipWriteSyncer(zapcore.AddSync(&out), 64)
+	logger := zap.New(zapcore.NewCore(NewTextEncoder(_optionsEncoderConfig), w, zap.DebugLevel))
+
+	expected := logLines(logger, 10)
+	// Only what was written since the last flush, less than a chunk, is
+	// still buffered.
+	assert.True(t, strings.HasPrefix(expected, gunzip(t, out.Bytes())), "Expected a prefix of the entries.")
+	assert.True(t, len(gunzip(t, out.Bytes())) >= len(expected)-64, "Expected all but the last chunk to be flushed.")
+
+	require.NoError(t, w.Close(), "Unexpected error closing.")
+	assert.Equal(t, expected, gunzip(t, out.Bytes()), "Expected every entry after Close.")
+}
@@ -0,0 +1,40 @@
+package zaptextencoder
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// FieldHook transforms the fields of every entry before it is encoded,
+// such as to add a request ID. Hooks are called concurrently by loggers
+// sharing an encoder, and must not modify the fields they are given in
+// place; they return a new slice instead.
+type FieldHook interface {
+	Hook(ent zapcore.Entry, fields []zapcore.Field) []zapcore.Field
+}
+
+// FieldHookFunc is a function implementing FieldHook.
+type FieldHookFunc func(ent zapcore.Entry, fields []zapcore.Field) []zapcore.Field
+
+// Hook calls f.
+func (f FieldHookFunc) Hook(ent zapcore.Entry, fields []zapcore.Field) []zapcore.Field {
+	return f(ent, fields)
+}
+
+// WithFieldHook adds hook to the hooks called, in the order they are
+// added, with the fields of every entry, whose result is encoded in their
+// place. Fields added by logger.With are not given to hooks.
+func WithFieldHook(hook FieldHook) Option {
+	return func(enc *textEncoder) {
+		if hook != nil {
+			enc.fieldHooks = append(enc.fieldHooks, hook)
+		}
+	}
+}
+
+// hookFields returns the fields of an entry transformed by the hooks.
+func (enc *textEncoder) hookFields(ent zapcore.Entry, fields []zapcore.Field) []zapcore.Field {
+	for _, hook := range enc.fieldHooks {
+		fields = hook.Hook(ent, fields)
+	}
+	return fields
+}
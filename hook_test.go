@@ -0,0 +1,49 @@
+package zaptextencoder
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func requestIDHook(id string) FieldHook {
+	return FieldHookFunc(func(ent zapcore.Entry, fields []zapcore.Field) []zapcore.Field {
+		out := make([]zapcore.Field, 0, len(fields)+1)
+		out = append(out, zap.String("request_id", id))
+		return append(out, fields...)
+	})
+}
+
+func TestWithFieldHook(t *testing.T) {
+	assertEncodedEntry(t, "error  lob law  request_id=\"req-1\"  k=1\n",
+		[]Option{WithFieldHook(requestIDHook("req-1"))}, zap.Int("k", 1))
+	assertEncodedEntry(t, "error  lob law  request_id=\"req-1\"\n",
+		[]Option{WithFieldHook(requestIDHook("req-1"))})
+
+	levelHook := FieldHookFunc(func(ent zapcore.Entry, fields []zapcore.Field) []zapcore.Field {
+		return append(fields[:len(fields):len(fields)], zap.Stringer("level", ent.Level))
+	})
+	assertEncodedEntry(t, "error  lob law  request_id=\"req-1\"  k=1  level=\"error\"\n",
+		[]Option{WithFieldHook(requestIDHook("req-1")), WithFieldHook(levelHook), WithFieldHook(nil)},
+		zap.Int("k", 1))
+}
+
+func TestWithFieldHookConcurrent(t *testing.T) {
+	enc := NewTextEncoder(_optionsEncoderConfig, WithFieldHook(requestIDHook("req-1"))).(TextEncoder)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s, err := enc.EncodeEntryString(_optionsEntry, []zapcore.Field{zap.Int("k", 1)})
+				assert.NoError(t, err)
+				assert.Equal(t, "error  lob law  request_id=\"req-1\"  k=1\n", s)
+			}
+		}()
+	}
+	wg.Wait()
+}
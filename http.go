@@ -0,0 +1,35 @@
+package zaptextencoder
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// HTTPRequestField returns a "request" object field describing r by its
+// method, path, query, remote_addr, proto, content_type and content_length,
+// leaving out the query and content type when empty. With
+// WithFlattenObjects, these are written as request.method=GET and so on. A
+// nil request is written as nothing.
+func HTTPRequestField(r *http.Request) zap.Field {
+	if r == nil {
+		return zap.Skip()
+	}
+	return zap.Object("request", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("method", r.Method)
+		if r.URL != nil {
+			enc.AddString("path", r.URL.Path)
+			if r.URL.RawQuery != "" {
+				enc.AddString("query", r.URL.RawQuery)
+			}
+		}
+		enc.AddString("remote_addr", r.RemoteAddr)
+		enc.AddString("proto", r.Proto)
+		if ct := r.Header.Get("Content-Type"); ct != "" {
+			enc.AddString("content_type", ct)
+		}
+		enc.AddInt64("content_length", r.ContentLength)
+		return nil
+	}))
+}
@@ -0,0 +1,27 @@
+package zaptextencoder
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPRequestField(t *testing.T) {
+	r := httptest.NewRequest("POST", "/api/v1?page=2", strings.NewReader("{}"))
+	r.Header.Set("Content-Type", "application/json")
+
+	assertEncodedEntry(t, "error  lob law"+
+		"  request.method=POST"+
+		"  request.path=/api/v1"+
+		"  request.query=\"page=2\""+
+		"  request.remote_addr=192.0.2.1:1234"+
+		"  request.proto=HTTP/1.1"+
+		"  request.content_type=application/json"+
+		"  request.content_length=2\n",
+		[]Option{WithFlattenObjects(true), WithQuoteMode(QuoteModeAuto)}, HTTPRequestField(r))
+
+	get := httptest.NewRequest("GET", "/", nil)
+	assertEncodedEntry(t, "error  lob law  request={method=\"GET\"  path=\"/\"  remote_addr=\"192.0.2.1:1234\"  proto=\"HTTP/1.1\"  content_length=0}\n",
+		nil, HTTPRequestField(get))
+	assertEncodedEntry(t, "error  lob law\n", nil, HTTPRequestField(nil))
+}
@@ -0,0 +1,62 @@
+package zaptextencoder
+
+import "sync"
+
+// DefaultKeyCache is a KeyCache to share between loggers, see WithKeyCache.
+var DefaultKeyCache = &KeyCache{}
+
+// KeyCache maps field keys to their escaped form, so that keys which are
+// logged over and over again, usually string constants, are only escaped
+// once. A KeyCache is safe for concurrent use. It never evicts anything,
+// so it's no good for keys built from unbounded data, such as user input.
+type KeyCache struct {
+	m sync.Map // map[string]string
+}
+
+// Load returns the escaped form of key, if it has been stored.
+func (c *KeyCache) Load(key string) (escaped string, ok bool) {
+	v, ok := c.m.Load(key)
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+// Store records escaped as the escaped form of key.
+func (c *KeyCache) Store(key, escaped string) {
+	c.m.Store(key, escaped)
+}
+
+// WithKeyCache looks up the escaped form of keys in cache before escaping
+// them, and stores those it misses. It pays off for keys that need escaping,
+// such as non-ASCII ones, and costs a lookup for plain ASCII keys, which are
+// cheaper to escape than to look up. As a KeyCache holds the default
+// escaping, the cache is bypassed by encoders which change it with
+// WithQuoteChar, WithEscapeStyle, WithASCIIOnly or
+// WithInvalidUTF8Replacement.
+func WithKeyCache(cache *KeyCache) Option {
+	return func(enc *textEncoder) {
+		enc.keyCache = cache
+	}
+}
+
+// addEscapedKey writes key escaped, through the KeyCache if there is one.
+func (enc *textEncoder) addEscapedKey(key string) {
+	if enc.keyCache == nil || !enc.defaultEscaping() {
+		enc.safeAddString(key)
+		return
+	}
+	if escaped, ok := enc.keyCache.Load(key); ok {
+		enc.buf.AppendString(escaped)
+		return
+	}
+	start := enc.buf.Len()
+	enc.safeAddString(key)
+	enc.keyCache.Store(key, string(enc.buf.Bytes()[start:]))
+}
+
+// defaultEscaping reports whether strings are escaped the default way.
+func (enc *textEncoder) defaultEscaping() bool {
+	return enc.quoteChar() == '"' && enc.escapeStyle == EscapeStyleJSON &&
+		!enc.asciiOnly && !enc.replaceInvalid
+}
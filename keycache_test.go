@@ -0,0 +1,38 @@
+package zaptextencoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithKeyCache(t *testing.T) {
+	cache := &KeyCache{}
+	fields := []zapcore.Field{zap.String("plain", "a"), zap.String("with \"quotes\"\n", "b")}
+	expected := "error  lob law  plain=\"a\"  with \\\"quotes\\\"\\n=\"b\"\n"
+
+	// The first entry fills the cache and the second reads from it.
+	for i := 0; i < 2; i++ {
+		assertEncodedEntry(t, expected, []Option{WithKeyCache(cache)}, fields...)
+	}
+	escaped, ok := cache.Load("with \"quotes\"\n")
+	if assert.True(t, ok, "Expected the key to be cached.") {
+		assert.Equal(t, `with \"quotes\"\n`, escaped, "Unexpected cached key.")
+	}
+
+	t.Run("cached keys are used", func(t *testing.T) {
+		cache := &KeyCache{}
+		cache.Store("k", "cached")
+		assertEncodedEntry(t, "error  lob law  cached=1\n", []Option{WithKeyCache(cache)}, zap.Int("k", 1))
+	})
+
+	t.Run("bypassed with custom escaping", func(t *testing.T) {
+		cache := &KeyCache{}
+		assertEncodedEntry(t, "error  lob law  it\\'s=1\n",
+			[]Option{WithKeyCache(cache), WithQuoteChar('\'')}, zap.Int("it's", 1))
+		_, ok := cache.Load("it's")
+		assert.False(t, ok, "Expected the cache to be bypassed.")
+	})
+}
@@ -0,0 +1,46 @@
+package zaptextencoder
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// lazyValue marks a field created by LazyField.
+type lazyValue struct {
+	fn func() interface{}
+}
+
+// LazyField logs the value returned by fn under key, as zap.Any would. fn
+// is only called when an entry is encoded, so that it costs nothing for
+// entries below the level of the logger. Like ContextField, it is written
+// as nothing by other encoders and when given to logger.With rather than to
+// a logging call.
+func LazyField(key string, fn func() interface{}) zap.Field {
+	return zap.Field{Key: key, Type: zapcore.SkipType, Interface: lazyValue{fn}}
+}
+
+// evalLazy returns fields with every LazyField replaced by the field of the
+// value returned by its function.
+func (enc *textEncoder) evalLazy(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		lv, ok := f.Interface.(lazyValue)
+		if !ok || f.Type != zapcore.SkipType {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		if lv.fn != nil {
+			out = append(out, zap.Any(f.Key, lv.fn()))
+		}
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+package zaptextencoder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLazyField(t *testing.T) {
+	value := func(v interface{}) func() interface{} {
+		return func() interface{} { return v }
+	}
+
+	assertEncodedEntry(t, "error  lob law  k=1  lazy=\"v\"  n=2\n", nil,
+		zap.Int("k", 1), LazyField("lazy", value("v")), zap.Int("n", 2))
+	assertEncodedEntry(t, "error  lob law  lazy=42\n", nil, LazyField("lazy", value(42)))
+	assertEncodedEntry(t, "error  lob law  lazy=[1,2]\n", nil, LazyField("lazy", value([]int{1, 2})))
+	assertEncodedEntry(t, "error  lob law  k=1\n", nil, zap.Int("k", 1), LazyField("lazy", nil))
+}
+
+func TestLazyFieldBelowLevel(t *testing.T) {
+	calls := 0
+	fn := func() interface{} {
+		calls++
+		return "v"
+	}
+	var out bytes.Buffer
+	logger := zap.New(zapcore.NewCore(NewTextEncoder(_optionsEncoderConfig), zapcore.AddSync(&out), zap.InfoLevel))
+
+	logger.Debug("lob law", LazyField("lazy", fn))
+	assert.Equal(t, 0, calls, "Expected the function not to be called below the level.")
+	assert.Empty(t, out.String(), "Expected no output below the level.")
+
+	logger.Info("lob law", LazyField("lazy", fn))
+	assert.Equal(t, 1, calls, "Expected the function to be called once.")
+	assert.Equal(t, "info   lob law  lazy=\"v\"\n", out.String(), "Unexpected output.")
+
+	// Other encoders write nothing in its place.
+	core, logs := observer.New(zap.DebugLevel)
+	zap.New(core).Info("lob law", LazyField("lazy", fn))
+	assert.Equal(t, 1, calls, "Expected other encoders not to call the function.")
+	assert.Equal(t, 1, logs.Len(), "Expected an entry.")
+}
@@ -0,0 +1,158 @@
+package zaptextencoder
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewLogfmtEncoder creates an encoder following logfmt, as described at
+// https://brandur.org/logfmt: every part of an entry is a key=value pair
+// separated by single spaces, including the time, level, name, caller,
+// function and message under the keys of cfg, such as
+//
+//	level=info ts=2018-06-19T16:33:42Z msg="lob law" answer=42
+//
+// Values holding spaces, '=', '"' or other such characters are quoted and
+// escaped, others are written as is. Arrays, objects and reflected fields
+// are written as quoted strings, and the stack trace as a field. Invalid
+// characters in keys are replaced with '_'. Opts apply as for
+// NewTextEncoder, but colors are left out of messages and times.
+func NewLogfmtEncoder(cfg zapcore.EncoderConfig, opts ...Option) zapcore.Encoder {
+	return NewTextEncoder(cfg, append([]Option{asLogfmt}, opts...)...)
+}
+
+func asLogfmt(enc *textEncoder) {
+	enc.logfmt = true
+	enc.separator = " "
+	enc.quoteMode = QuoteModeAuto
+}
+
+func (enc *textEncoder) encodeLogfmtEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := enc.clone()
+	final.buf.AppendString(enc.linePrefix)
+	final.fieldStart = final.buf.Len()
+
+	arr := getSliceEncoder()
+	if enc.TimeKey != "" && enc.EncodeTime != nil {
+		enc.EncodeTime(ent.Time, arr)
+		final.addHeaderField(enc.TimeKey, arr)
+	}
+	if enc.LevelKey != "" && enc.EncodeLevel != nil {
+		enc.EncodeLevel(ent.Level, arr)
+		final.addHeaderField(enc.LevelKey, arr)
+	}
+	if ent.LoggerName != "" && enc.NameKey != "" {
+		nameEncoder := enc.EncodeName
+		if nameEncoder == nil {
+			nameEncoder = zapcore.FullNameEncoder
+		}
+		nameEncoder(ent.LoggerName, arr)
+		final.addHeaderField(enc.NameKey, arr)
+	}
+	if ent.Caller.Defined {
+		if enc.CallerKey != "" && enc.EncodeCaller != nil {
+			enc.EncodeCaller(ent.Caller, arr)
+			final.addHeaderField(enc.CallerKey, arr)
+		}
+		if enc.FunctionKey != "" {
+			final.AddString(enc.FunctionKey, ent.Caller.Function)
+		}
+	}
+	putSliceEncoder(arr)
+	if enc.MessageKey != "" {
+		final.AddString(enc.MessageKey, ent.Message)
+	}
+
+	ctx := enc.context()
+	if enc.dedup != DeduplicateNone {
+		ctx, fields = enc.deduplicate(fields)
+	}
+	if len(ctx) > 0 {
+		if final.buf.Len() > final.fieldStart {
+			final.buf.AppendString(final.separator)
+		}
+		final.buf.Write(ctx)
+	}
+	final.addFields(enc.orderFields(fields))
+
+	if ent.Stack != "" && final.StacktraceKey != "" {
+		final.AddString(final.StacktraceKey, enc.formatStacktrace(ent.Stack))
+	}
+	return final.endEntry(), nil
+}
+
+// addHeaderField adds the elements written to arr by an encoder of the
+// EncoderConfig as the value of key, joined by spaces if there are several,
+// and empties arr.
+func (enc *textEncoder) addHeaderField(key string, arr *sliceArrayEncoder) {
+	defer func() { arr.elems = arr.elems[:0] }()
+	if len(arr.elems) == 0 || !enc.addKey(key) {
+		return
+	}
+	if len(arr.elems) > 1 {
+		parts := make([]string, len(arr.elems))
+		for i, elem := range arr.elems {
+			parts[i] = fmt.Sprint(elem)
+		}
+		enc.AppendString(strings.Join(parts, " "))
+		enc.closeKey(key)
+		return
+	}
+	switch elem := arr.elems[0].(type) {
+	case string:
+		enc.AppendString(elem)
+	case float64:
+		enc.AppendFloat64(elem)
+	case int64:
+		enc.AppendInt64(elem)
+	case bool:
+		enc.AppendBool(elem)
+	default:
+		enc.AppendString(fmt.Sprint(elem))
+	}
+	enc.closeKey(key)
+}
+
+// addLogfmtValue adds the value written by add to a scratch encoder as a
+// string, as logfmt values cannot hold the spaces and '=' of arrays and
+// objects unquoted.
+func (enc *textEncoder) addLogfmtValue(key string, add func(scratch *textEncoder) error) error {
+	if !enc.addKey(key) {
+		return nil
+	}
+	scratch := enc.clone()
+	err := add(scratch)
+	enc.AppendByteString(scratch.buf.Bytes())
+	enc.buffers().Put(scratch.buf)
+	putTextEncoder(scratch)
+	enc.closeKey(key)
+	return err
+}
+
+// logfmtKey returns key with the characters logfmt does not allow in keys,
+// spaces, '=', '"' and control characters, replaced by '_'.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	valid := func(r rune) bool {
+		return r > ' ' && r != '=' && r != '"' && r != utf8.RuneError &&
+			!unicode.IsSpace(r) && !unicode.IsControl(r)
+	}
+	for _, r := range key {
+		if !valid(r) {
+			return strings.Map(func(r rune) rune {
+				if valid(r) {
+					return r
+				}
+				return '_'
+			}, key)
+		}
+	}
+	return key
+}
@@ -0,0 +1,167 @@
+package zaptextencoder
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type logfmtPair struct {
+	key, value string
+}
+
+// parseLogfmt splits a logfmt line into its pairs, unquoting values, and
+// fails on anything outside of the grammar at https://brandur.org/logfmt.
+func parseLogfmt(t *testing.T, line string) []logfmtPair {
+	isIdent := func(c byte) bool { return c > ' ' && c != '=' && c != '"' }
+	var pairs []logfmtPair
+	for i := 0; i < len(line); {
+		if line[i] == ' ' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(line) && isIdent(line[i]) {
+			i++
+		}
+		require.True(t, i > start, "Expected a key at %d in %q.", start, line)
+		pair := logfmtPair{key: line[start:i]}
+		if i < len(line) && line[i] == '=' {
+			i++
+			switch {
+			case i < len(line) && line[i] == '"':
+				end := i + 1
+				for end < len(line) && line[end] != '"' {
+					if line[end] == '\\' {
+						end++
+					}
+					end++
+				}
+				require.True(t, end < len(line), "Unterminated quoted value at %d in %q.", i, line)
+				value, err := strconv.Unquote(line[i : end+1])
+				require.NoError(t, err, "Invalid quoted value %s in %q.", line[i:end+1], line)
+				pair.value = value
+				i = end + 1
+			default:
+				start = i
+				for i < len(line) && isIdent(line[i]) {
+					i++
+				}
+				pair.value = line[start:i]
+			}
+		}
+		require.True(t, i == len(line) || line[i] == ' ', "Expected a space at %d in %q.", i, line)
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+func TestLogfmtEncoderHeader(t *testing.T) {
+	cfg := zapcore.EncoderConfig{
+		TimeKey:       "ts",
+		LevelKey:      "level",
+		NameKey:       "logger",
+		CallerKey:     "caller",
+		FunctionKey:   "func",
+		MessageKey:    "msg",
+		StacktraceKey: "stacktrace",
+		EncodeTime:    zapcore.ISO8601TimeEncoder,
+		EncodeLevel:   zapcore.LowercaseLevelEncoder,
+		EncodeCaller:  zapcore.ShortCallerEncoder,
+	}
+	enc := NewLogfmtEncoder(cfg)
+	enc.AddString("ctx", "with")
+	buf, err := enc.EncodeEntry(zapcore.Entry{
+		Level:      zapcore.InfoLevel,
+		Time:       time.Date(2018, 6, 19, 16, 33, 42, 0, time.UTC),
+		LoggerName: "main",
+		Message:    "lob law",
+		Caller:     zapcore.NewEntryCaller(0, "/src/app/main.go", 42, true),
+		Stack:      "main.main\n\t/src/app/main.go:42",
+	}, []zapcore.Field{zap.Int("answer", 42)})
+	require.NoError(t, err, "Unexpected logfmt encoding error.")
+	defer buf.Free()
+
+	assert.Equal(t, `ts=2018-06-19T16:33:42.000Z level=info logger=main caller=app/main.go:42 func="" msg="lob law" ctx=with answer=42 stacktrace="main.main\n\t/src/app/main.go:42"`+"\n", buf.String())
+	parseLogfmt(t, strings.TrimSuffix(buf.String(), "\n"))
+}
+
+func TestLogfmtEncoderFields(t *testing.T) {
+	tests := []struct {
+		desc     string
+		field    zapcore.Field
+		expected string
+	}{
+		{"string", zap.String("k", "v"), "v"},
+		{"string with space", zap.String("k", "a b"), "a b"},
+		{"string with equals", zap.String("k", "a=b"), "a=b"},
+		{"string with quote", zap.String("k", `say "hi"`), `say "hi"`},
+		{"string with newline", zap.String("k", "a\nb"), "a\nb"},
+		{"empty string", zap.String("k", ""), ""},
+		{"byte string", zap.ByteString("k", []byte("a b")), "a b"},
+		{"binary", zap.Binary("k", []byte("ab")), "YWI="},
+		{"bool", zap.Bool("k", true), "true"},
+		{"int", zap.Int("k", -42), "-42"},
+		{"uint", zap.Uint("k", 42), "42"},
+		{"float", zap.Float64("k", 1.5), "1.5"},
+		{"NaN", zap.Float64("k", math.NaN()), "NaN"},
+		{"complex", zap.Complex128("k", 1+2i), "1+2i"},
+		{"duration", zap.Duration("k", time.Second), "1000000000"},
+		{"time", zap.Time("k", time.Unix(0, 0)), "0"},
+		{"error", zap.NamedError("k", errors.New("oh no")), "oh no"},
+		{"stringer", zap.Stringer("k", time.Second), "1s"},
+		{"array", zap.Strings("k", []string{"a", "b c"}), `[a,"b c"]`},
+		{"object", zap.Object("k", loginUser{"bob", "x"}), `{name=bob password=x}`},
+		{"reflect", zap.Reflect("k", map[string]int{"a": 1}), `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewLogfmtEncoder(_optionsEncoderConfig)
+			buf, err := enc.EncodeEntry(_optionsEntry, []zapcore.Field{tt.field})
+			require.NoError(t, err, "Unexpected logfmt encoding error.")
+			defer buf.Free()
+
+			pairs := parseLogfmt(t, strings.TrimSuffix(buf.String(), "\n"))
+			assert.Equal(t, []logfmtPair{
+				{"L", "error"},
+				{"M", "lob law"},
+				{"k", tt.expected},
+			}, pairs, "Unexpected pairs in %q.", buf.String())
+		})
+	}
+}
+
+func TestLogfmtKey(t *testing.T) {
+	tests := []struct {
+		key, expected string
+	}{
+		{"plain", "plain"},
+		{"with space", "with_space"},
+		{"a=b", "a_b"},
+		{`q"uote`, "q_uote"},
+		{"tab\there", "tab_here"},
+		{"nbsp ", "nbsp_"},
+		{"invalid\xff", "invalid_"},
+		{"", "_"},
+		{"ünicode", "ünicode"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, logfmtKey(tt.key), "Unexpected logfmt key for %q.", tt.key)
+	}
+
+	enc := NewLogfmtEncoder(_optionsEncoderConfig)
+	buf, err := enc.EncodeEntry(_optionsEntry, []zapcore.Field{zap.Int("bad key=", 1)})
+	require.NoError(t, err, "Unexpected logfmt encoding error.")
+	assert.Equal(t, "L=error M=\"lob law\" bad_key_=1\n", buf.String())
+	buf.Free()
+}
@@ -0,0 +1,48 @@
+package zaptextencoder_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hms58/zaptextencoder"
+	"github.com/hms58/zaptextencoder/zaptexttest"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerIntegration(t *testing.T) {
+	logger, observed := zaptexttest.NewTestLogger(t,
+		zaptextencoder.WithDeduplicateStrategy(zaptextencoder.DeduplicateLastWins))
+
+	child := logger.Named("api").With(zap.String("service", "users"), zap.Int("attempt", 1))
+	child.Info("request",
+		zap.Int("attempt", 2),
+		zap.Duration("elapsed", 1500*time.Millisecond),
+		zap.Strings("roles", []string{"admin", "dev"}),
+		zap.Object("user", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("name", "bob")
+			enc.AddBool("active", true)
+			return nil
+		})),
+	)
+	logger.Debug("done")
+
+	entries := observed.Entries()
+	if assert.Len(t, entries, 2, "Unexpected number of entries.") {
+		assert.Equal(t, map[string]string{
+			zaptexttest.LevelKey:   "info",
+			zaptexttest.NameKey:    "api",
+			zaptexttest.MessageKey: "request",
+			"service":              "users",
+			"attempt":              "2",
+			"elapsed":              "1.5s",
+			"roles":                `["admin","dev"]`,
+			"user":                 `{name="bob"  active=true}`,
+		}, entries[0], "Unexpected first entry.")
+		assert.Equal(t, map[string]string{
+			zaptexttest.LevelKey:   "debug",
+			zaptexttest.MessageKey: "done",
+		}, entries[1], "Unexpected second entry.")
+	}
+}
@@ -0,0 +1,61 @@
+package zaptextencoder
+
+import (
+	"expvar"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelCounter is called with the level of every entry encoded, such as to
+// count them in a metric.
+type LevelCounter func(level zapcore.Level)
+
+// WithLevelCounter adds counter to the counters called, once per entry,
+// before it is encoded.
+func WithLevelCounter(counter LevelCounter) Option {
+	return func(enc *textEncoder) {
+		if counter != nil {
+			enc.levelCounters = append(enc.levelCounters, counter)
+		}
+	}
+}
+
+// countLevel calls the level counters of enc.
+func (enc *textEncoder) countLevel(level zapcore.Level) {
+	for _, count := range enc.levelCounters {
+		count(level)
+	}
+}
+
+// expvarMu serializes the lookup and publication of expvar counters, as
+// expvar.NewInt panics if the name is taken.
+var expvarMu sync.Mutex
+
+// WithExpvarCounting counts the entries of each level in the expvar
+// variables name_debug_total, name_info_total and so on, served at
+// /debug/vars along with the others. Encoders counting under the same name
+// share the variables. A name taken by a variable which isn't an
+// *expvar.Int is left alone, and its level isn't counted.
+func WithExpvarCounting(name string) Option {
+	var counters [zapcore.FatalLevel - zapcore.DebugLevel + 1]*expvar.Int
+	expvarMu.Lock()
+	for i := range counters {
+		key := name + "_" + (zapcore.DebugLevel + zapcore.Level(i)).String() + "_total"
+		if v := expvar.Get(key); v != nil {
+			counters[i], _ = v.(*expvar.Int)
+		} else {
+			counters[i] = expvar.NewInt(key)
+		}
+	}
+	expvarMu.Unlock()
+
+	return WithLevelCounter(func(level zapcore.Level) {
+		if level < zapcore.DebugLevel || level > zapcore.FatalLevel {
+			return
+		}
+		if c := counters[level-zapcore.DebugLevel]; c != nil {
+			c.Add(1)
+		}
+	})
+}
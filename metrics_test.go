@@ -0,0 +1,63 @@
+package zaptextencoder
+
+import (
+	"expvar"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithLevelCounter(t *testing.T) {
+	var levels []zapcore.Level
+	enc := NewTextEncoder(_optionsEncoderConfig, WithLevelCounter(func(level zapcore.Level) {
+		levels = append(levels, level)
+	}), WithLevelCounter(nil))
+	logger := zap.New(zapcore.NewCore(enc, zapcore.AddSync(ioutil.Discard), zap.InfoLevel))
+
+	logger.Debug("dropped")
+	logger.Info("lob law")
+	// Conditional fields mustn't count an entry twice.
+	logger.With(ConditionalField(zap.ErrorLevel, zap.Int("k", 1))).Error("lob law")
+	assert.Equal(t, []zapcore.Level{zap.InfoLevel, zap.ErrorLevel}, levels, "Unexpected levels counted.")
+}
+
+func TestWithExpvarCounting(t *testing.T) {
+	count := func(level string) int64 {
+		v := expvar.Get("zaptext_test_" + level + "_total")
+		require.NotNil(t, v, "Expected a %s counter.", level)
+		return v.(*expvar.Int).Value()
+	}
+
+	// The variables outlive the test, which may run more than once.
+	enc := NewTextEncoder(_optionsEncoderConfig, WithExpvarCounting("zaptext_test"))
+	debug, info, errors, fatal := count("debug"), count("info"), count("error"), count("fatal")
+	logger := zap.New(zapcore.NewCore(enc, zapcore.AddSync(ioutil.Discard), zap.DebugLevel))
+	logger.Debug("lob law")
+	logger.Debug("lob law")
+	logger.Error("lob law")
+	assert.Equal(t, debug+2, count("debug"), "Unexpected debug count.")
+	assert.Equal(t, errors+1, count("error"), "Unexpected error count.")
+	assert.Equal(t, info, count("info"), "Unexpected info count.")
+	assert.Equal(t, fatal, count("fatal"), "Unexpected fatal count.")
+
+	// Registering the name again shares the counters.
+	other := NewTextEncoder(_optionsEncoderConfig, WithExpvarCounting("zaptext_test"))
+	zap.New(zapcore.NewCore(other, zapcore.AddSync(ioutil.Discard), zap.DebugLevel)).Error("lob law")
+	assert.Equal(t, errors+2, count("error"), "Expected encoders to share the counters.")
+
+	// Names taken by other variables are left alone.
+	if expvar.Get("zaptext_taken_info_total") == nil {
+		expvar.NewString("zaptext_taken_info_total").Set("taken")
+	}
+	taken := NewTextEncoder(_optionsEncoderConfig, WithExpvarCounting("zaptext_taken"))
+	warn := expvar.Get("zaptext_taken_warn_total").(*expvar.Int).Value()
+	logger = zap.New(zapcore.NewCore(taken, zapcore.AddSync(ioutil.Discard), zap.DebugLevel))
+	logger.Info("lob law")
+	logger.Warn("lob law")
+	assert.Equal(t, "\"taken\"", expvar.Get("zaptext_taken_info_total").String(), "Expected the variable to be left alone.")
+	assert.Equal(t, warn+1, expvar.Get("zaptext_taken_warn_total").(*expvar.Int).Value(), "Unexpected warn count.")
+}
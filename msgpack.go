@@ -0,0 +1,206 @@
+package zaptextencoder
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/tinylib/msgp/msgp"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// msgpackEncoder writes entries as MessagePack maps, see NewMsgPackEncoder.
+// namespaces are the keys of the namespaces opened so far, in which the
+// fields of the embedded encoder go.
+type msgpackEncoder struct {
+	*zapcore.MapObjectEncoder
+	*zapcore.EncoderConfig
+	namespaces []string
+}
+
+// NewMsgPackEncoder creates an encoder writing every entry as a MessagePack
+// map, with the time, level, name, caller, function, message and stack
+// trace under the keys of cfg, encoded as cfg asks, followed by the fields
+// in key order. Namespaces and objects are nested maps and arrays are
+// arrays. Times and durations are encoded by cfg.EncodeTime and
+// cfg.EncodeDuration, or as nanoseconds if nil. Fields with the key of a
+// header value are left out, and entries are written back to back without
+// line endings, as MessagePack values delimit themselves.
+func NewMsgPackEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return &msgpackEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder(), EncoderConfig: &cfg}
+}
+
+func (enc *msgpackEncoder) OpenNamespace(key string) {
+	enc.MapObjectEncoder.OpenNamespace(key)
+	enc.namespaces = append(enc.namespaces[:len(enc.namespaces):len(enc.namespaces)], key)
+}
+
+func (enc *msgpackEncoder) Clone() zapcore.Encoder {
+	clone := &msgpackEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder(), EncoderConfig: enc.EncoderConfig, namespaces: enc.namespaces}
+	// Copy the maps holding the open namespaces, which fields are still
+	// added to, and share the others.
+	src, dst := enc.Fields, clone.Fields
+	for _, ns := range enc.namespaces {
+		for k, v := range src {
+			if k != ns {
+				dst[k] = v
+			}
+		}
+		clone.MapObjectEncoder.OpenNamespace(ns)
+		src, _ = src[ns].(map[string]interface{})
+		dst = dst[ns].(map[string]interface{})
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return clone
+}
+
+func (enc *msgpackEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := enc.Clone().(*msgpackEncoder)
+	for i := range fields {
+		fields[i].AddTo(final)
+	}
+
+	var keys []string
+	header := make(map[string]interface{})
+	arr := getSliceEncoder()
+	addHeader := func(key string, encode func()) {
+		if key == "" {
+			return
+		}
+		encode()
+		switch len(arr.elems) {
+		case 0:
+			return
+		case 1:
+			header[key] = arr.elems[0]
+		default:
+			header[key] = append([]interface{}(nil), arr.elems...)
+		}
+		keys = append(keys, key)
+		arr.elems = arr.elems[:0]
+	}
+	if enc.EncodeTime != nil {
+		addHeader(enc.TimeKey, func() { enc.EncodeTime(ent.Time, arr) })
+	}
+	if enc.EncodeLevel != nil {
+		addHeader(enc.LevelKey, func() { enc.EncodeLevel(ent.Level, arr) })
+	}
+	if ent.LoggerName != "" {
+		nameEncoder := enc.EncodeName
+		if nameEncoder == nil {
+			nameEncoder = zapcore.FullNameEncoder
+		}
+		addHeader(enc.NameKey, func() { nameEncoder(ent.LoggerName, arr) })
+	}
+	if ent.Caller.Defined {
+		if enc.EncodeCaller != nil {
+			addHeader(enc.CallerKey, func() { enc.EncodeCaller(ent.Caller, arr) })
+		}
+		addHeader(enc.FunctionKey, func() { arr.AppendString(ent.Caller.Function) })
+	}
+	addHeader(enc.MessageKey, func() { arr.AppendString(ent.Message) })
+	if ent.Stack != "" {
+		addHeader(enc.StacktraceKey, func() { arr.AppendString(ent.Stack) })
+	}
+	putSliceEncoder(arr)
+
+	size := len(keys)
+	for k := range final.Fields {
+		if _, ok := header[k]; !ok {
+			size++
+		}
+	}
+	b := msgp.AppendMapHeader(nil, uint32(size))
+	var err error
+	for _, k := range keys {
+		b = msgp.AppendString(b, k)
+		if b, err = enc.appendValue(b, header[k]); err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range sortedKeys(final.Fields) {
+		if _, ok := header[k]; ok {
+			continue
+		}
+		b = msgp.AppendString(b, k)
+		if b, err = enc.appendValue(b, final.Fields[k]); err != nil {
+			return nil, err
+		}
+	}
+
+	buf := bufferPool.Get()
+	buf.Write(b)
+	bufferPool.handOff()
+	return buf, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// appendValue appends v, as added to a zapcore.MapObjectEncoder, to b.
+func (enc *msgpackEncoder) appendValue(b []byte, v interface{}) ([]byte, error) {
+	var err error
+	switch v := v.(type) {
+	case map[string]interface{}:
+		b = msgp.AppendMapHeader(b, uint32(len(v)))
+		for _, k := range sortedKeys(v) {
+			b = msgp.AppendString(b, k)
+			if b, err = enc.appendValue(b, v[k]); err != nil {
+				return b, err
+			}
+		}
+		return b, nil
+	case []interface{}:
+		b = msgp.AppendArrayHeader(b, uint32(len(v)))
+		for _, elem := range v {
+			if b, err = enc.appendValue(b, elem); err != nil {
+				return b, err
+			}
+		}
+		return b, nil
+	case time.Time:
+		if enc.EncodeTime == nil {
+			return msgp.AppendInt64(b, v.UnixNano()), nil
+		}
+		return enc.appendEncoded(b, func(arr zapcore.PrimitiveArrayEncoder) { enc.EncodeTime(v, arr) })
+	case time.Duration:
+		if enc.EncodeDuration == nil {
+			return msgp.AppendInt64(b, int64(v)), nil
+		}
+		return enc.appendEncoded(b, func(arr zapcore.PrimitiveArrayEncoder) { enc.EncodeDuration(v, arr) })
+	case nil, string, []byte, bool, float32, float64, complex64, complex128,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr:
+		return msgp.AppendIntf(b, v)
+	}
+	// Reflected values are written as their JSON encoding would decode.
+	data, err := json.Marshal(v)
+	if err != nil {
+		return b, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return b, err
+	}
+	return enc.appendValue(b, decoded)
+}
+
+// appendEncoded appends the elements written by encode, the single one or
+// an array of several.
+func (enc *msgpackEncoder) appendEncoded(b []byte, encode func(zapcore.PrimitiveArrayEncoder)) ([]byte, error) {
+	arr := getSliceEncoder()
+	defer putSliceEncoder(arr)
+	encode(arr)
+	if len(arr.elems) == 1 {
+		return enc.appendValue(b, arr.elems[0])
+	}
+	return enc.appendValue(b, arr.elems)
+}
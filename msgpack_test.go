@@ -0,0 +1,101 @@
+package zaptextencoder
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func decodeMsgPack(t *testing.T, enc zapcore.Encoder, ent zapcore.Entry, fields ...zapcore.Field) map[string]interface{} {
+	buf, err := enc.EncodeEntry(ent, fields)
+	require.NoError(t, err, "Unexpected MessagePack encoding error.")
+	defer buf.Free()
+
+	m, rest, err := msgp.ReadMapStrIntfBytes(buf.Bytes(), nil)
+	require.NoError(t, err, "Expected a MessagePack map, got %x.", buf.Bytes())
+	assert.Empty(t, rest, "Expected a single map per entry.")
+	return m
+}
+
+func TestMsgPackEncoder(t *testing.T) {
+	cfg := zapcore.EncoderConfig{
+		TimeKey:        "ts",
+		LevelKey:       "level",
+		NameKey:        "logger",
+		MessageKey:     "msg",
+		EncodeTime:     zapcore.EpochTimeEncoder,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	}
+	enc := NewMsgPackEncoder(cfg)
+	enc.AddString("service", "api")
+	ent := zapcore.Entry{
+		Level:      zapcore.WarnLevel,
+		Time:       time.Unix(1529426022, 0),
+		LoggerName: "db",
+		Message:    "lob law",
+	}
+
+	m := decodeMsgPack(t, enc, ent,
+		zap.Int("answer", 42),
+		zap.Uint64("big", math.MaxUint64),
+		zap.Float64("nan", math.NaN()),
+		zap.Bool("ok", true),
+		zap.Binary("bin", []byte{1, 2}),
+		zap.Duration("took", time.Second),
+		zap.Strings("tags", []string{"a", "b"}),
+		zap.Object("obj", event{"start", 1}),
+		zap.Reflect("refl", []int{1, 2}),
+		zap.String("msg", "shadowed"),
+		zap.Namespace("req"),
+		zap.String("id", "abc"),
+	)
+
+	require.Contains(t, m, "nan", "Expected the NaN field.")
+	assert.True(t, math.IsNaN(m["nan"].(float64)), "Expected NaN to round-trip.")
+	delete(m, "nan")
+	assert.Equal(t, map[string]interface{}{
+		"ts":      float64(1529426022),
+		"level":   "warn",
+		"logger":  "db",
+		"msg":     "lob law",
+		"service": "api",
+		"answer":  int64(42),
+		"big":     uint64(math.MaxUint64),
+		"ok":      true,
+		"bin":     []byte{1, 2},
+		"took":    "1s",
+		"tags":    []interface{}{"a", "b"},
+		"obj":     map[string]interface{}{"name": "start", "id": int64(1)},
+		"refl":    []interface{}{float64(1), float64(2)},
+		"req":     map[string]interface{}{"id": "abc"},
+	}, m, "Unexpected decoded entry.")
+}
+
+func TestMsgPackEncoderClone(t *testing.T) {
+	enc := NewMsgPackEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+	enc.AddString("service", "api")
+	enc.OpenNamespace("req")
+	enc.AddString("id", "abc")
+
+	clone := enc.Clone()
+	clone.AddInt("attempt", 2)
+	ent := zapcore.Entry{Message: "lob law"}
+
+	assert.Equal(t, map[string]interface{}{
+		"msg":     "lob law",
+		"service": "api",
+		"req":     map[string]interface{}{"id": "abc", "attempt": int64(2)},
+	}, decodeMsgPack(t, clone, ent), "Expected the clone to add fields to the open namespace.")
+	assert.Equal(t, map[string]interface{}{
+		"msg":     "lob law",
+		"service": "api",
+		"req":     map[string]interface{}{"id": "abc"},
+	}, decodeMsgPack(t, enc, ent), "Expected the clone to leave the original alone.")
+}
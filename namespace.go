@@ -0,0 +1,74 @@
+package zaptextencoder
+
+import (
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// WithNamespaceSeparator sets the string joining namespaces opened by
+// zap.Namespace to each other and to the keys of the fields that follow,
+// such as "a.b.key=value". The default is "."; empty separators are
+// ignored.
+func WithNamespaceSeparator(sep string) Option {
+	return func(enc *textEncoder) {
+		if sep != "" {
+			enc.namespaceSep = sep
+		}
+	}
+}
+
+// WithFlattenObjects writes the fields of objects added with zap.Object as
+// siblings of the object, prefixed with its key as a namespace, such as
+// address.street="..." address.city="..." instead of address={...}. Objects
+// in arrays are left as is.
+func WithFlattenObjects(enabled bool) Option {
+	return func(enc *textEncoder) {
+		enc.flattenObjects = enabled
+	}
+}
+
+// addFlatObject adds the fields of obj in the namespace key, see
+// WithFlattenObjects.
+func (enc *textEncoder) addFlatObject(key string, obj zapcore.ObjectMarshaler) error {
+	n := len(enc.namespaces)
+	enc.OpenNamespace(key)
+	err := obj.MarshalLogObject(enc)
+	enc.namespaces = enc.namespaces[:n]
+	return err
+}
+
+// OpenNamespace prefixes the keys of all fields added afterwards with key.
+// A namespace opened inside an object or array ends with it.
+func (enc *textEncoder) OpenNamespace(key string) {
+	enc.namespaces = append(enc.namespaces, key)
+}
+
+// namespaceSeparator returns the string joining namespaces and keys.
+func (enc *textEncoder) namespaceSeparator() string {
+	if enc.namespaceSep == "" {
+		return "."
+	}
+	return enc.namespaceSep
+}
+
+// namespacedKey returns key prefixed with the open namespaces of enc.
+func (enc *textEncoder) namespacedKey(key string) string {
+	if len(enc.namespaces) == 0 {
+		return key
+	}
+	sep := enc.namespaceSeparator()
+	return strings.Join(enc.namespaces, sep) + sep + key
+}
+
+// enterNested starts an array or object, in which the open namespaces of
+// enc do not apply. The returned function restores them.
+func (enc *textEncoder) enterNested() func() {
+	namespaces := enc.namespaces
+	enc.namespaces = nil
+	enc.depth++
+	return func() {
+		enc.depth--
+		enc.namespaces = namespaces
+	}
+}
@@ -0,0 +1,156 @@
+package zaptextencoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestOpenNamespace(t *testing.T) {
+	tests := []struct {
+		desc     string
+		opts     []Option
+		fields   []zapcore.Field
+		expected string
+	}{
+		{
+			desc:     "nested",
+			fields:   []zapcore.Field{zap.Int("top", 1), zap.Namespace("a"), zap.Namespace("b"), zap.String("key", "value")},
+			expected: "error  lob law  top=1  a.b.key=\"value\"\n",
+		},
+		{
+			desc:     "custom separator",
+			opts:     []Option{WithNamespaceSeparator("_")},
+			fields:   []zapcore.Field{zap.Namespace("a"), zap.Namespace("b"), zap.String("key", "value")},
+			expected: "error  lob law  a_b_key=\"value\"\n",
+		},
+		{
+			desc:     "path separator",
+			opts:     []Option{WithNamespaceSeparator("/")},
+			fields:   []zapcore.Field{zap.Namespace("level1"), zap.Namespace("level2"), zap.String("key", "value")},
+			expected: "error  lob law  level1/level2/key=\"value\"\n",
+		},
+		{
+			desc:     "multi-character separator",
+			opts:     []Option{WithNamespaceSeparator("::")},
+			fields:   []zapcore.Field{zap.Namespace("level1"), zap.Namespace("level2"), zap.String("key", "value")},
+			expected: "error  lob law  level1::level2::key=\"value\"\n",
+		},
+		{
+			desc:     "empty separator is ignored",
+			opts:     []Option{WithNamespaceSeparator("")},
+			fields:   []zapcore.Field{zap.Namespace("a"), zap.String("key", "value")},
+			expected: "error  lob law  a.key=\"value\"\n",
+		},
+		{
+			desc: "scoped to objects",
+			fields: []zapcore.Field{
+				zap.Namespace("a"),
+				zap.Object("obj", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+					enc.AddInt("x", 1)
+					enc.OpenNamespace("inner")
+					enc.AddInt("y", 2)
+					return nil
+				})),
+				zap.Int("z", 3),
+			},
+			expected: "error  lob law  a.obj={x=1  inner.y=2}  a.z=3\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assertEncodedEntry(t, tt.expected, tt.opts, tt.fields...)
+		})
+	}
+}
+
+func TestOpenNamespaceClone(t *testing.T) {
+	enc := NewTextEncoder(_optionsEncoderConfig)
+	enc.OpenNamespace("a")
+	clone := enc.Clone()
+	clone.OpenNamespace("b")
+	enc.OpenNamespace("c")
+	clone.AddString("key", "clone")
+	enc.AddString("key", "original")
+
+	buf, err := clone.EncodeEntry(_optionsEntry, []zapcore.Field{zap.Int("n", 1)})
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		assert.Equal(t, "error  a.b.key=\"clone\"  lob law  a.b.n=1\n", buf.String())
+	}
+	buf.Free()
+
+	buf, err = enc.EncodeEntry(_optionsEntry, nil)
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		assert.Equal(t, "error  a.c.key=\"original\"  lob law\n", buf.String())
+	}
+	buf.Free()
+}
+
+type address struct {
+	street, city string
+}
+
+func (a address) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("street", a.street)
+	enc.AddString("city", a.city)
+	return nil
+}
+
+type customer struct {
+	name string
+	home address
+}
+
+func (c customer) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", c.name)
+	return enc.AddObject("address", c.home)
+}
+
+func TestWithFlattenObjects(t *testing.T) {
+	c := customer{"bob", address{"1 Main St", "Springfield"}}
+	tests := []struct {
+		desc     string
+		opts     []Option
+		fields   []zapcore.Field
+		expected string
+	}{
+		{
+			desc:     "flat",
+			opts:     []Option{WithFlattenObjects(true)},
+			fields:   []zapcore.Field{zap.Object("address", c.home), zap.Int("n", 1)},
+			expected: "error  lob law  address.street=\"1 Main St\"  address.city=\"Springfield\"  n=1\n",
+		},
+		{
+			desc:     "nested",
+			opts:     []Option{WithFlattenObjects(true)},
+			fields:   []zapcore.Field{zap.Object("customer", c)},
+			expected: "error  lob law  customer.name=\"bob\"  customer.address.street=\"1 Main St\"  customer.address.city=\"Springfield\"\n",
+		},
+		{
+			desc:     "in namespace",
+			opts:     []Option{WithFlattenObjects(true)},
+			fields:   []zapcore.Field{zap.Namespace("req"), zap.Object("address", c.home)},
+			expected: "error  lob law  req.address.street=\"1 Main St\"  req.address.city=\"Springfield\"\n",
+		},
+		{
+			desc:     "arrays left as is",
+			opts:     []Option{WithFlattenObjects(true)},
+			fields:   []zapcore.Field{zap.Array("addresses", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error { return enc.AppendObject(c.home) }))},
+			expected: "error  lob law  addresses=[{street=\"1 Main St\"  city=\"Springfield\"}]\n",
+		},
+		{
+			desc:     "disabled",
+			fields:   []zapcore.Field{zap.Object("address", c.home)},
+			expected: "error  lob law  address={street=\"1 Main St\"  city=\"Springfield\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assertEncodedEntry(t, tt.expected, tt.opts, tt.fields...)
+		})
+	}
+}
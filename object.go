@@ -0,0 +1,73 @@
+package zaptextencoder
+
+import (
+	"bytes"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ObjectEncoding selects how fields added with zap.Object are written.
+type ObjectEncoding int
+
+const (
+	// ObjectEncodingText writes the fields of an object with the usual
+	// key=value encoding, wrapped in braces: k={a=1  b=2}. This is the
+	// default.
+	ObjectEncodingText ObjectEncoding = iota
+	// ObjectEncodingJSON writes objects as JSON, using the time, duration
+	// and other encoders of the EncoderConfig: k={"a":1,"b":2}. The
+	// options that act on keys and values, such as WithSensitiveKeys, don't
+	// apply inside the JSON.
+	ObjectEncodingJSON
+)
+
+// WithObjectEncoding sets the encoding of objects, including objects in
+// arrays.
+func WithObjectEncoding(mode ObjectEncoding) Option {
+	return func(enc *textEncoder) {
+		enc.objectEncoding = mode
+	}
+}
+
+// WithObjectDelimiters sets the strings written before and after the fields
+// of an object, in place of the braces of either ObjectEncoding. Passing two
+// empty strings keeps the braces.
+func WithObjectDelimiters(begin, end string) Option {
+	return func(enc *textEncoder) {
+		enc.objectBegin, enc.objectEnd = begin, end
+	}
+}
+
+// objectDelimiters returns the strings written around the fields of an
+// object.
+func (enc *textEncoder) objectDelimiters() (begin, end string) {
+	if enc.objectBegin == "" && enc.objectEnd == "" {
+		return "{", "}"
+	}
+	return enc.objectBegin, enc.objectEnd
+}
+
+// appendJSONObject writes obj with zap's JSON encoder. The JSON encoder
+// only exposes its output through EncodeEntry, so obj is added under an
+// empty key to an encoder without entry keys, and the wrapping object is
+// cut off again, along with the object's own braces.
+func (enc *textEncoder) appendJSONObject(obj zapcore.ObjectMarshaler) error {
+	cfg := *enc.EncoderConfig
+	cfg.MessageKey, cfg.LevelKey, cfg.TimeKey, cfg.NameKey = "", "", "", ""
+	cfg.CallerKey, cfg.FunctionKey, cfg.StacktraceKey = "", "", ""
+	cfg.LineEnding = "\n"
+	jsonEnc := zapcore.NewJSONEncoder(cfg)
+	err := jsonEnc.AddObject("", obj)
+	buf, encErr := jsonEnc.EncodeEntry(zapcore.Entry{}, nil)
+	if encErr != nil {
+		return encErr
+	}
+	out := bytes.TrimPrefix(buf.Bytes(), []byte(`{"":{`))
+	out = bytes.TrimSuffix(out, []byte("}}\n"))
+	begin, end := enc.objectDelimiters()
+	enc.buf.AppendString(begin)
+	enc.buf.Write(out)
+	enc.buf.AppendString(end)
+	buf.Free()
+	return err
+}
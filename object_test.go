@@ -0,0 +1,141 @@
+package zaptextencoder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type event struct {
+	name string
+	id   int
+}
+
+func (e event) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", e.name)
+	enc.AddInt("id", e.id)
+	return nil
+}
+
+func TestTextEncoderObjectArrays(t *testing.T) {
+	events := zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, e := range []event{{"start", 1}, {"stop", 2}} {
+			if err := arr.AppendObject(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	nested := zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		return arr.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("batch", "a")
+			return enc.AddArray("events", events)
+		}))
+	})
+
+	tests := []struct {
+		desc     string
+		field    zapcore.Field
+		expected string
+	}{
+		{"empty", zap.Array("events", zapcore.ArrayMarshalerFunc(func(zapcore.ArrayEncoder) error { return nil })), "error  lob law  events=[]\n"},
+		{"objects", zap.Array("events", events), "error  lob law  events=[{name=\"start\"  id=1},{name=\"stop\"  id=2}]\n"},
+		{"nested", zap.Array("batches", nested), "error  lob law  batches=[{batch=\"a\"  events=[{name=\"start\"  id=1},{name=\"stop\"  id=2}]}]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assertEncodedEntry(t, tt.expected, nil, tt.field)
+		})
+	}
+}
+
+func TestWithObjectEncoding(t *testing.T) {
+	tests := []struct {
+		desc     string
+		opts     []Option
+		field    zapcore.Field
+		expected string
+	}{
+		{
+			desc:     "text",
+			opts:     []Option{WithFieldSeparator(" ")},
+			field:    zap.Object("k", event{"start", 1}),
+			expected: "error lob law k={name=\"start\" id=1}\n",
+		},
+		{
+			desc:     "json",
+			opts:     []Option{WithObjectEncoding(ObjectEncodingJSON)},
+			field:    zap.Object("k", event{"start", 1}),
+			expected: "error  lob law  k={\"name\":\"start\",\"id\":1}\n",
+		},
+		{
+			desc: "json in array",
+			opts: []Option{WithObjectEncoding(ObjectEncodingJSON)},
+			field: zap.Array("k", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+				_ = arr.AppendObject(event{"start", 1})
+				return arr.AppendObject(event{"stop", 2})
+			})),
+			expected: "error  lob law  k=[{\"name\":\"start\",\"id\":1},{\"name\":\"stop\",\"id\":2}]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assertEncodedEntry(t, tt.expected, tt.opts, tt.field)
+		})
+	}
+}
+
+func TestWithObjectEncodingJSONError(t *testing.T) {
+	enc := NewTextEncoder(_optionsEncoderConfig, WithObjectEncoding(ObjectEncodingJSON))
+	err := enc.AddObject("k", zapcore.ObjectMarshalerFunc(func(inner zapcore.ObjectEncoder) error {
+		inner.AddString("name", "start")
+		return errors.New("fail")
+	}))
+	assert.Error(t, err, "Expected the marshaler's error.")
+	buf, err := enc.EncodeEntry(_optionsEntry, nil)
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		assert.Equal(t, "error  k={\"name\":\"start\"}  lob law\n", buf.String(), "Incorrect encoded text entry.")
+	}
+	buf.Free()
+}
+
+func TestWithObjectDelimiters(t *testing.T) {
+	obj := zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("name", "start")
+		enc.AddInt("id", 1)
+		return nil
+	})
+
+	tests := []struct {
+		desc     string
+		opts     []Option
+		expected string
+	}{
+		{
+			desc:     "text",
+			opts:     []Option{WithObjectDelimiters("(", ")")},
+			expected: "error  lob law  k=(name=\"start\"  id=1)\n",
+		},
+		{
+			desc:     "json",
+			opts:     []Option{WithObjectDelimiters("<", ">"), WithObjectEncoding(ObjectEncodingJSON)},
+			expected: "error  lob law  k=<\"name\":\"start\",\"id\":1>\n",
+		},
+		{
+			desc:     "empty is ignored",
+			opts:     []Option{WithObjectDelimiters("", "")},
+			expected: "error  lob law  k={name=\"start\"  id=1}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assertEncodedEntry(t, tt.expected, tt.opts, zap.Object("k", obj))
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package zaptextencoder
+
+import "go.uber.org/zap/zapcore"
+
+// FieldObserver is called with every field added to an ObservingEncoder,
+// such as to audit them or detect PII. value is of the type the field was
+// created from, such as an int64 for AddInt64, see ObservingEncoder.
+type FieldObserver func(key string, fieldType zapcore.FieldType, value interface{})
+
+// ObservingEncoder is a zapcore.Encoder calling a FieldObserver with every
+// field added to the encoder it wraps, by logger.With or a logging call,
+// before adding it unchanged. The fields of objects are observed with their
+// own keys, and the elements of arrays with the key of their array.
+type ObservingEncoder struct {
+	*interceptingEncoder
+}
+
+// WrapWithObserver returns an ObservingEncoder calling obs with the fields
+// added to enc, or enc itself if obs is nil.
+func WrapWithObserver(enc zapcore.Encoder, obs FieldObserver) zapcore.Encoder {
+	if obs == nil {
+		return enc
+	}
+	return &ObservingEncoder{newInterceptingEncoder(enc, func(f zapcore.Field) (zapcore.Field, bool) {
+		obs(f.Key, f.Type, fieldValue(f))
+		return f, true
+	})}
+}
+
+// Clone returns an ObservingEncoder wrapping a clone of the encoder.
+func (e *ObservingEncoder) Clone() zapcore.Encoder {
+	return &ObservingEncoder{e.clone()}
+}
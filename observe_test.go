@@ -0,0 +1,73 @@
+package zaptextencoder
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type observedField struct {
+	key       string
+	fieldType zapcore.FieldType
+	value     interface{}
+}
+
+type observedInts []int64
+
+func (ints observedInts) MarshalLogArray(arr zapcore.ArrayEncoder) error {
+	for _, i := range ints {
+		arr.AppendInt64(i)
+	}
+	return nil
+}
+
+func TestWrapWithObserver(t *testing.T) {
+	var observed []observedField
+	obs := func(key string, fieldType zapcore.FieldType, value interface{}) {
+		observed = append(observed, observedField{key, fieldType, value})
+	}
+	ints := observedInts{1, 2}
+	obj := event{"start", 1}
+	at := time.Unix(1, 0).UTC()
+	err := errors.New("fail")
+	fields := []zapcore.Field{
+		zap.Int64("n", 42),
+		zap.Reflect("r", []int{3}),
+		zap.Array("a", ints),
+		zap.Object("o", obj),
+		zap.Time("t", at),
+		zap.Error(err),
+	}
+
+	var out, expected bytes.Buffer
+	enc := WrapWithObserver(NewTextEncoder(_optionsEncoderConfig), obs)
+	logger := zap.New(zapcore.NewCore(enc, zapcore.AddSync(&out), zap.DebugLevel))
+	logger.With(zap.String("s", "with")).Info("lob law", fields...)
+	plain := zap.New(zapcore.NewCore(NewTextEncoder(_optionsEncoderConfig), zapcore.AddSync(&expected), zap.DebugLevel))
+	plain.With(zap.String("s", "with")).Info("lob law", fields...)
+	assert.Equal(t, expected.String(), out.String(), "Expected the output of the wrapped encoder.")
+
+	assert.Equal(t, []observedField{
+		{"s", zapcore.StringType, "with"},
+		{"n", zapcore.Int64Type, int64(42)},
+		{"r", zapcore.ReflectType, []int{3}},
+		{"a", zapcore.ArrayMarshalerType, ints},
+		{"o", zapcore.ObjectMarshalerType, obj},
+		{"t", zapcore.TimeType, at},
+		{"error", zapcore.ErrorType, err},
+		// Arrays and objects are observed as they're encoded.
+		{"a", zapcore.Int64Type, int64(1)},
+		{"a", zapcore.Int64Type, int64(2)},
+		{"name", zapcore.StringType, "start"},
+		{"id", zapcore.Int64Type, int64(1)},
+	}, observed, "Unexpected observed fields.")
+
+	assert.IsType(t, &ObservingEncoder{}, enc.Clone(), "Expected clones to observe fields too.")
+	plainEnc := NewTextEncoder(_optionsEncoderConfig)
+	assert.Equal(t, plainEnc, WrapWithObserver(plainEnc, nil), "Expected no wrapper without an observer.")
+}
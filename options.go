@@ -0,0 +1,324 @@
+package zaptextencoder
+
+import "golang.org/x/text/unicode/norm"
+
+// Option configures the encoder returned by NewTextEncoder.
+type Option func(*textEncoder)
+
+// WithFieldSeparator sets the string written between the header elements,
+// the message and each key=value field. The default is two spaces. An empty
+// separator would run fields together, so it is ignored.
+func WithFieldSeparator(sep string) Option {
+	return func(enc *textEncoder) {
+		if sep != "" {
+			enc.separator = sep
+		}
+	}
+}
+
+// WithKeyValueDelimiter sets the string written between each key and its
+// value. The default is "=". An empty delimiter is ignored.
+func WithKeyValueDelimiter(delim string) Option {
+	return func(enc *textEncoder) {
+		if delim != "" {
+			enc.keyDelim = delim
+		}
+	}
+}
+
+// WithArrayElementSeparator sets the string written between the elements
+// of an array, such as " " for [1 2 3]. The default is ",". An empty
+// separator is ignored.
+func WithArrayElementSeparator(sep string) Option {
+	return func(enc *textEncoder) {
+		if sep != "" {
+			enc.arraySep = sep
+		}
+	}
+}
+
+// WithArrayDelimiters sets the strings written before and after the
+// elements of an array. The default is "[" and "]". Passing two empty
+// strings keeps the default.
+func WithArrayDelimiters(begin, end string) Option {
+	return func(enc *textEncoder) {
+		enc.arrayBegin, enc.arrayEnd = begin, end
+	}
+}
+
+// QuoteMode controls when string values are wrapped in quotes, see
+// WithQuoteChar.
+type QuoteMode int
+
+const (
+	// QuoteModeAlways quotes every string value. This is the default.
+	QuoteModeAlways QuoteMode = iota
+	// QuoteModeAuto quotes only the string values that would otherwise be
+	// ambiguous, such as empty strings and strings containing whitespace,
+	// the field separator or "=".
+	QuoteModeAuto
+)
+
+// WithQuoteMode sets when string values are quoted.
+func WithQuoteMode(mode QuoteMode) Option {
+	return func(enc *textEncoder) {
+		enc.quoteMode = mode
+	}
+}
+
+// WithQuoteChar sets the character that quoted strings are wrapped in, such
+// as a single quote for shell scripts:
+//
+//	WithQuoteChar('\'')
+//
+// The character is escaped with a backslash inside the string, in place of
+// '"'. The default is '"'. Characters other than '"', '`' and the single
+// quote are ignored.
+func WithQuoteChar(ch rune) Option {
+	return func(enc *textEncoder) {
+		switch ch {
+		case '"', '\'', '`':
+			enc.quote = byte(ch)
+		}
+	}
+}
+
+// EscapeStyle selects how the quote character is escaped inside quoted
+// strings.
+type EscapeStyle int
+
+const (
+	// EscapeStyleJSON escapes the quote character with a backslash, as in
+	// "say \"hi\"". This is the default.
+	EscapeStyleJSON EscapeStyle = iota
+	// EscapeStyleDouble escapes the quote character by doubling it, as in
+	// "say ""hi""". Backslashes and control characters are still escaped
+	// with a backslash.
+	EscapeStyleDouble
+)
+
+// WithEscapeStyle sets how the quote character is escaped inside quoted
+// strings.
+func WithEscapeStyle(style EscapeStyle) Option {
+	return func(enc *textEncoder) {
+		enc.escapeStyle = style
+	}
+}
+
+// WithASCIIOnly escapes every non-ASCII character of keys and values as
+// \uXXXX, or as \UXXXXXXXX outside the Basic Multilingual Plane, for tools
+// that can only handle ASCII.
+func WithASCIIOnly(enabled bool) Option {
+	return func(enc *textEncoder) {
+		enc.asciiOnly = enabled
+	}
+}
+
+// WithUnicodeNormalization normalizes string values to form before they are
+// truncated, redacted and written, so that the same text always looks the
+// same. Forms other than norm.NFC, norm.NFD, norm.NFKC and norm.NFKD are
+// ignored. Values are written as they are by default.
+func WithUnicodeNormalization(form norm.Form) Option {
+	return func(enc *textEncoder) {
+		switch form {
+		case norm.NFC, norm.NFD, norm.NFKC, norm.NFKD:
+			enc.normalize, enc.normForm = true, form
+		}
+	}
+}
+
+// WithInvalidUTF8Replacement sets what is written in place of each byte
+// of a string that isn't valid UTF-8, such as "?". An empty replacement
+// drops invalid bytes. The default is the escape sequence \ufffd.
+func WithInvalidUTF8Replacement(replacement string) Option {
+	return func(enc *textEncoder) {
+		enc.replaceInvalid, enc.invalidReplacement = true, replacement
+	}
+}
+
+// WithMaxValueLen truncates string values to at most n runes, followed by
+// the truncation suffix. Truncation never splits a multi-byte rune. Zero or
+// a negative n disables truncation, which is the default.
+func WithMaxValueLen(n int) Option {
+	return func(enc *textEncoder) {
+		enc.maxValueLen = n
+	}
+}
+
+// WithMaxValueBytes truncates string values to at most n bytes, before
+// escaping, followed by the truncation suffix. As with WithMaxValueLen, the
+// cut is moved back to the start of a multi-byte rune rather than split it,
+// so fewer than n bytes may be kept. Zero or a negative n disables it, which
+// is the default.
+func WithMaxValueBytes(n int) Option {
+	return func(enc *textEncoder) {
+		enc.maxValueBytes = n
+	}
+}
+
+// WithTruncationSuffix sets the string appended to values truncated by
+// WithMaxValueLen or WithMaxValueBytes. The default is "…"; an empty suffix
+// appends nothing.
+func WithTruncationSuffix(s string) Option {
+	return func(enc *textEncoder) {
+		enc.truncSuffix = s
+	}
+}
+
+// WithLinePrefix writes prefix as is at the start of every entry, before
+// the time and other header fields, such as "[APP] " to tell apart the
+// logs of several programs sharing an output.
+func WithLinePrefix(prefix string) Option {
+	return func(enc *textEncoder) {
+		enc.linePrefix = prefix
+	}
+}
+
+// LineEnding is written at the end of every entry.
+type LineEnding string
+
+const (
+	// LineEndingLF ends entries with a line feed. It is the default.
+	LineEndingLF LineEnding = "\n"
+	// LineEndingCRLF ends entries with a carriage return and a line feed.
+	LineEndingCRLF LineEnding = "\r\n"
+)
+
+// WithLineEnding sets the string written at the end of every entry,
+// overriding the LineEnding of the EncoderConfig.
+func WithLineEnding(ending LineEnding) Option {
+	return func(enc *textEncoder) {
+		enc.LineEnding = string(ending)
+	}
+}
+
+// WithNoTrailingNewline leaves out the line ending of every entry, for
+// sinks that frame records themselves.
+func WithNoTrailingNewline() Option {
+	return func(enc *textEncoder) {
+		enc.noLineEnding = true
+	}
+}
+
+// WithMaxFields caps the number of fields written for each entry, not
+// counting those added by logger.With. The remaining fields are replaced by
+// a single fields_dropped field holding their count. Zero, the default,
+// disables the limit.
+func WithMaxFields(n int) Option {
+	return func(enc *textEncoder) {
+		enc.maxFields = n
+	}
+}
+
+// BinaryEncoding selects how binary values are turned into text.
+type BinaryEncoding int
+
+const (
+	// BinaryBase64 uses standard, padded base64. This is the default.
+	BinaryBase64 BinaryEncoding = iota
+	// BinaryBase64URL uses padded, URL-safe base64.
+	BinaryBase64URL
+	// BinaryHex uses lowercase hexadecimal.
+	BinaryHex
+	// BinaryHexUpper uses uppercase hexadecimal.
+	BinaryHexUpper
+)
+
+// WithBinaryEncoding sets the encoding of binary values.
+func WithBinaryEncoding(enc BinaryEncoding) Option {
+	return func(e *textEncoder) {
+		e.binaryEncoding = enc
+	}
+}
+
+// BoolEncoding selects the words bool values are written as.
+type BoolEncoding int
+
+const (
+	// BoolTrueFalse writes true and false. This is the default.
+	BoolTrueFalse BoolEncoding = iota
+	// BoolYesNo writes yes and no.
+	BoolYesNo
+	// BoolOnOff writes on and off.
+	BoolOnOff
+	// BoolOneZero writes 1 and 0.
+	BoolOneZero
+)
+
+// WithBoolEncoding sets the encoding of bool values.
+func WithBoolEncoding(enc BoolEncoding) Option {
+	return func(e *textEncoder) {
+		e.boolEncoding = enc
+	}
+}
+
+// WithFloatPrecision sets the number of digits written after the decimal
+// point of float values. The default of -1 uses the fewest digits needed
+// to represent the value exactly.
+func WithFloatPrecision(p int) Option {
+	return func(enc *textEncoder) {
+		if p < 0 {
+			enc.floatPrecision, enc.fixedFloatPrecision = 0, false
+			return
+		}
+		enc.floatPrecision, enc.fixedFloatPrecision = p, true
+	}
+}
+
+// FloatFormat selects the notation of float values.
+type FloatFormat int
+
+const (
+	// FloatFormatDecimal writes floats without an exponent, such as
+	// 10000000000. This is the default.
+	FloatFormatDecimal FloatFormat = iota
+	// FloatFormatScientific writes floats with an exponent, such as 1e+10.
+	FloatFormatScientific
+	// FloatFormatShortest picks whichever of the two is shorter, using an
+	// exponent for large exponents only.
+	FloatFormatShortest
+)
+
+// verb returns the strconv.FormatFloat format byte of f.
+func (f FloatFormat) verb() byte {
+	switch f {
+	case FloatFormatScientific:
+		return 'e'
+	case FloatFormatShortest:
+		return 'g'
+	default:
+		return 'f'
+	}
+}
+
+// WithFloatFormat sets the notation of float values. NaN and infinities are
+// written as NaN, +Inf and -Inf in any format.
+func WithFloatFormat(f FloatFormat) Option {
+	return func(enc *textEncoder) {
+		enc.floatFormat = f
+	}
+}
+
+// IntEncoding selects the base integers are written in.
+type IntEncoding int
+
+const (
+	// IntDecimal writes integers in base 10. This is the default.
+	IntDecimal IntEncoding = iota
+	// IntHex writes integers in lowercase base 16, prefixed with "0x".
+	IntHex
+	// IntHexUpper writes integers in uppercase base 16, prefixed with "0x".
+	IntHexUpper
+	// IntOctal writes integers in base 8, prefixed with "0o".
+	IntOctal
+	// IntBinary writes integers in base 2, prefixed with "0b".
+	IntBinary
+)
+
+// WithIntEncoding sets the base of every integer the encoder writes,
+// signed or not. Negative values keep their sign in front of the prefix.
+func WithIntEncoding(enc IntEncoding) Option {
+	return func(e *textEncoder) {
+		e.intEncoding = enc
+	}
+}
@@ -0,0 +1,595 @@
+package zaptextencoder
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/text/unicode/norm"
+)
+
+var _optionsEncoderConfig = zapcore.EncoderConfig{
+	MessageKey:  "M",
+	LevelKey:    "L",
+	EncodeLevel: zapcore.LowercaseLevelEncoder,
+}
+
+var _optionsEntry = zapcore.Entry{
+	Level:   zapcore.ErrorLevel,
+	Time:    time.Date(2018, 6, 19, 16, 33, 42, 99, time.UTC),
+	Message: "lob law",
+}
+
+func assertEncodedEntry(t *testing.T, expected string, opts []Option, fields ...zapcore.Field) {
+	enc := NewTextEncoder(_optionsEncoderConfig, opts...)
+	buf, err := enc.EncodeEntry(_optionsEntry, fields)
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		assert.Equal(t, expected, buf.String(), "Incorrect encoded text entry.")
+	}
+	buf.Free()
+}
+
+func TestWithFieldSeparator(t *testing.T) {
+	tests := []struct {
+		desc     string
+		sep      string
+		expected string
+	}{
+		{"single space", " ", "error lob law so=\"passes\" answer=42\n"},
+		{"pipe", "|", "error|lob law|so=\"passes\"|answer=42\n"},
+		{"tab", "\t", "error\tlob law\tso=\"passes\"\tanswer=42\n"},
+		{"empty is ignored", "", "error  lob law  so=\"passes\"  answer=42\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assertEncodedEntry(t, tt.expected, []Option{WithFieldSeparator(tt.sep)},
+				zap.String("so", "passes"),
+				zap.Int("answer", 42),
+			)
+		})
+	}
+}
+
+func TestWithKeyValueDelimiter(t *testing.T) {
+	tests := []struct {
+		desc     string
+		delim    string
+		expected string
+	}{
+		{"colon", ":", "error  lob law  so:\"passes\"  answer:42  ids:[1,2]\n"},
+		{"arrow", " => ", "error  lob law  so => \"passes\"  answer => 42  ids => [1,2]\n"},
+		{"empty is ignored", "", "error  lob law  so=\"passes\"  answer=42  ids=[1,2]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assertEncodedEntry(t, tt.expected, []Option{WithKeyValueDelimiter(tt.delim)},
+				zap.String("so", "passes"),
+				zap.Int("answer", 42),
+				zap.Ints("ids", []int{1, 2}),
+			)
+		})
+	}
+}
+
+func TestWithArrayElementSeparator(t *testing.T) {
+	nested := zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		_ = arr.AppendArray(zapcore.ArrayMarshalerFunc(func(inner zapcore.ArrayEncoder) error {
+			inner.AppendInt(1)
+			inner.AppendInt(2)
+			return nil
+		}))
+		return arr.AppendArray(zapcore.ArrayMarshalerFunc(func(inner zapcore.ArrayEncoder) error {
+			inner.AppendInt(3)
+			return nil
+		}))
+	})
+	fields := []zapcore.Field{
+		zap.Bools("bools", []bool{true, false}),
+		zap.Strings("strs", []string{"a", "b"}),
+		zap.Ints("ints", []int{1, 2, 3}),
+		zap.Array("nested", nested),
+	}
+
+	tests := []struct {
+		desc     string
+		opts     []Option
+		expected string
+	}{
+		{
+			desc:     "default",
+			expected: "error  lob law  bools=[true,false]  strs=[\"a\",\"b\"]  ints=[1,2,3]  nested=[[1,2],[3]]\n",
+		},
+		{
+			desc:     "space",
+			opts:     []Option{WithArrayElementSeparator(" ")},
+			expected: "error  lob law  bools=[true false]  strs=[\"a\" \"b\"]  ints=[1 2 3]  nested=[[1 2] [3]]\n",
+		},
+		{
+			desc:     "pipe and parentheses",
+			opts:     []Option{WithArrayElementSeparator("|"), WithArrayDelimiters("(", ")")},
+			expected: "error  lob law  bools=(true|false)  strs=(\"a\"|\"b\")  ints=(1|2|3)  nested=((1|2)|(3))\n",
+		},
+		{
+			desc:     "empty is ignored",
+			opts:     []Option{WithArrayElementSeparator(""), WithArrayDelimiters("", "")},
+			expected: "error  lob law  bools=[true,false]  strs=[\"a\",\"b\"]  ints=[1,2,3]  nested=[[1,2],[3]]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assertEncodedEntry(t, tt.expected, tt.opts, fields...)
+		})
+	}
+}
+
+func TestWithQuoteMode(t *testing.T) {
+	tests := []struct {
+		desc     string
+		val      string
+		expected string
+	}{
+		{"alphanumeric", "running", `k=running`},
+		{"embedded space", "two words", `k="two words"`},
+		{"embedded tab", "two\twords", `k="two\twords"`},
+		{"unicode space", "two\u00a0words", "k=\"two\u00a0words\""},
+		{"separator", "a|b", `k="a|b"`},
+		{"delimiter", "a=b", `k="a=b"`},
+		{"quote", `say "hi"`, `k="say \"hi\""`},
+		{"array syntax", "[1]", `k="[1]"`},
+		{"empty", "", `k=""`},
+		{"non-ASCII", "☃", `k=☃`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(zapcore.EncoderConfig{}, WithQuoteMode(QuoteModeAuto), WithFieldSeparator("|")).(*textEncoder)
+			enc.AddString("k", tt.val)
+			assertText(t, tt.expected, enc)
+
+			enc.truncate()
+			enc.AddByteString("k", []byte(tt.val))
+			assertText(t, tt.expected, enc)
+		})
+	}
+
+	t.Run("arrays", func(t *testing.T) {
+		enc := NewTextEncoder(zapcore.EncoderConfig{}, WithQuoteMode(QuoteModeAuto)).(*textEncoder)
+		assert.NoError(t, enc.AddArray("k", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			arr.AppendString("a")
+			arr.AppendString("b c")
+			arr.AppendString("d")
+			return nil
+		})))
+		assertText(t, `k=[a,"b c",d]`, enc)
+	})
+
+	t.Run("always", func(t *testing.T) {
+		enc := NewTextEncoder(zapcore.EncoderConfig{}, WithQuoteMode(QuoteModeAlways)).(*textEncoder)
+		enc.AddString("k", "running")
+		assertText(t, `k="running"`, enc)
+	})
+}
+
+func TestWithQuoteChar(t *testing.T) {
+	tests := []struct {
+		desc     string
+		ch       rune
+		val      string
+		expected string
+	}{
+		{"single", '\'', "two words", `k='two words'`},
+		{"single escaped", '\'', "it's", `k='it\'s'`},
+		{"double left alone", '\'', `say "hi"`, `k='say "hi"'`},
+		{"backslash", '\'', `a\b`, `k='a\\b'`},
+		{"backtick", '`', "two words", "k=`two words`"},
+		{"default", '"', `say "hi"`, `k="say \"hi\""`},
+		{"invalid is ignored", 'x', `say "hi"`, `k="say \"hi\""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(zapcore.EncoderConfig{}, WithQuoteChar(tt.ch)).(*textEncoder)
+			enc.AddString("k", tt.val)
+			assertText(t, tt.expected, enc)
+
+			enc.truncate()
+			enc.AddByteString("k", []byte(tt.val))
+			assertText(t, tt.expected, enc)
+		})
+	}
+
+	t.Run("complex", func(t *testing.T) {
+		enc := NewTextEncoder(zapcore.EncoderConfig{}, WithQuoteChar('\'')).(*textEncoder)
+		enc.AddComplex128("k", 1+2i)
+		assertText(t, `k='1+2i'`, enc)
+	})
+}
+
+func TestWithEscapeStyle(t *testing.T) {
+	tests := []struct {
+		desc     string
+		opts     []Option
+		val      string
+		expected string
+	}{
+		{"json", nil, `say "hello"`, `k="say \"hello\""`},
+		{"double", []Option{WithEscapeStyle(EscapeStyleDouble)}, `say "hello"`, `k="say ""hello"""`},
+		{"double control characters", []Option{WithEscapeStyle(EscapeStyleDouble)}, "a\\b\n\"", `k="a\\b\n"""`},
+		{"double single quote", []Option{WithEscapeStyle(EscapeStyleDouble), WithQuoteChar('\'')}, `it's "x"`, `k='it''s "x"'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(zapcore.EncoderConfig{}, tt.opts...).(*textEncoder)
+			enc.AddString("k", tt.val)
+			assertText(t, tt.expected, enc)
+
+			enc.truncate()
+			enc.AddByteString("k", []byte(tt.val))
+			assertText(t, tt.expected, enc)
+		})
+	}
+}
+
+func TestWithASCIIOnly(t *testing.T) {
+	tests := []struct {
+		desc     string
+		val      string
+		expected string
+	}{
+		{"ascii", "plain text", `k="plain text"`},
+		{"snowman", "☃", `k="\u2603"`},
+		{"cyrillic", "привет", `k="\u043f\u0440\u0438\u0432\u0435\u0442"`},
+		{"chinese", "你好", `k="\u4f60\u597d"`},
+		{"astral", "😀", `k="\U0001f600"`},
+		{"invalid", "a\xed", `k="a\ufffd"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(zapcore.EncoderConfig{}, WithASCIIOnly(true)).(*textEncoder)
+			enc.AddString("k", tt.val)
+			assertText(t, tt.expected, enc)
+
+			enc.truncate()
+			enc.AddByteString("k", []byte(tt.val))
+			assertText(t, tt.expected, enc)
+		})
+	}
+
+	t.Run("quoted in auto mode", func(t *testing.T) {
+		enc := NewTextEncoder(zapcore.EncoderConfig{}, WithASCIIOnly(true), WithQuoteMode(QuoteModeAuto)).(*textEncoder)
+		enc.AddString("k", "☃")
+		assertText(t, `k="\u2603"`, enc)
+	})
+}
+
+func TestWithUnicodeNormalization(t *testing.T) {
+	const (
+		decomposed = "cafe\u0301"
+		composed   = "caf\u00e9"
+	)
+	tests := []struct {
+		desc     string
+		opts     []Option
+		val      string
+		expected string
+	}{
+		{"not set", nil, decomposed, `k="` + decomposed + `"`},
+		{"nfc", []Option{WithUnicodeNormalization(norm.NFC)}, decomposed, `k="` + composed + `"`},
+		{"nfd", []Option{WithUnicodeNormalization(norm.NFD)}, composed, `k="` + decomposed + `"`},
+		{"nfkc", []Option{WithUnicodeNormalization(norm.NFKC)}, "\ufb01", `k="fi"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(zapcore.EncoderConfig{}, tt.opts...).(*textEncoder)
+			enc.AddString("k", tt.val)
+			assertText(t, tt.expected, enc)
+
+			enc.truncate()
+			enc.AddByteString("k", []byte(tt.val))
+			assertText(t, tt.expected, enc)
+		})
+	}
+}
+
+func TestWithMaxValueLen(t *testing.T) {
+	tests := []struct {
+		desc     string
+		opts     []Option
+		val      string
+		expected string
+	}{
+		{"disabled", nil, "abcdef", `k="abcdef"`},
+		{"zero disables", []Option{WithMaxValueLen(0)}, "abcdef", `k="abcdef"`},
+		{"shorter than limit", []Option{WithMaxValueLen(5)}, "abc", `k="abc"`},
+		{"ASCII at exact boundary", []Option{WithMaxValueLen(3)}, "abc", `k="abc"`},
+		{"ASCII past boundary", []Option{WithMaxValueLen(3)}, "abcd", `k="abc…"`},
+		{"emoji", []Option{WithMaxValueLen(2)}, "💩🤔🙊", `k="💩🤔…"`},
+		{"emoji at exact boundary", []Option{WithMaxValueLen(3)}, "💩🤔🙊", `k="💩🤔🙊"`},
+		{"mixed width", []Option{WithMaxValueLen(3)}, "a☃💩b", `k="a☃💩…"`},
+		{"escaped runes", []Option{WithMaxValueLen(2)}, "\n\t\r", `k="\n\t…"`},
+		{"custom suffix", []Option{WithMaxValueLen(3), WithTruncationSuffix("[...]")}, "abcdef", `k="abc[...]"`},
+		{"empty suffix", []Option{WithMaxValueLen(3), WithTruncationSuffix("")}, "abcdef", `k="abc"`},
+		{
+			desc:     "suffix is quoted in auto mode",
+			opts:     []Option{WithMaxValueLen(3), WithTruncationSuffix(" (more)"), WithQuoteMode(QuoteModeAuto)},
+			val:      "abcdef",
+			expected: `k="abc (more)"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(zapcore.EncoderConfig{}, tt.opts...).(*textEncoder)
+			enc.AddString("k", tt.val)
+			assertText(t, tt.expected, enc)
+
+			enc.truncate()
+			enc.AddByteString("k", []byte(tt.val))
+			assertText(t, tt.expected, enc)
+		})
+	}
+}
+
+func TestWithMaxValueBytes(t *testing.T) {
+	tests := []struct {
+		desc     string
+		opts     []Option
+		val      string
+		expected string
+	}{
+		{"disabled", nil, "abcdef", `k="abcdef"`},
+		{"ASCII at exact boundary", []Option{WithMaxValueBytes(3)}, "abc", `k="abc"`},
+		{"ASCII past boundary", []Option{WithMaxValueBytes(3)}, "abcd", `k="abc…"`},
+		{"CJK at rune boundary", []Option{WithMaxValueBytes(6)}, "日本語", `k="日本…"`},
+		{"CJK mid rune", []Option{WithMaxValueBytes(7)}, "日本語", `k="日本…"`},
+		{"CJK before first rune ends", []Option{WithMaxValueBytes(2)}, "日本語", `k="…"`},
+		{"runes are stricter", []Option{WithMaxValueBytes(9), WithMaxValueLen(1)}, "日本語", `k="日…"`},
+		{"bytes are stricter", []Option{WithMaxValueBytes(4), WithMaxValueLen(2)}, "日本語", `k="日…"`},
+		{"invalid bytes", []Option{WithMaxValueBytes(2)}, "a\x80\x80\x80\x80", `k="a\ufffd…"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(zapcore.EncoderConfig{}, tt.opts...).(*textEncoder)
+			enc.AddString("k", tt.val)
+			assertText(t, tt.expected, enc)
+
+			enc.truncate()
+			enc.AddByteString("k", []byte(tt.val))
+			assertText(t, tt.expected, enc)
+		})
+	}
+
+	t.Run("valid UTF-8 under the limit", func(t *testing.T) {
+		val := strings.Repeat("日本語", 10)
+		for limit := 1; limit < len(val); limit++ {
+			i := (&textEncoder{maxValueBytes: limit}).truncateStringIndex(val)
+			assert.True(t, i <= limit, "Kept %d bytes with a limit of %d.", i, limit)
+			assert.True(t, utf8.ValidString(val[:i]), "Split a rune with a limit of %d.", limit)
+		}
+	})
+}
+
+func TestWithBoolEncoding(t *testing.T) {
+	tests := []struct {
+		desc        string
+		enc         BoolEncoding
+		true, false string
+	}{
+		{"true/false", BoolTrueFalse, "true", "false"},
+		{"yes/no", BoolYesNo, "yes", "no"},
+		{"on/off", BoolOnOff, "on", "off"},
+		{"1/0", BoolOneZero, "1", "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(zapcore.EncoderConfig{}, WithBoolEncoding(tt.enc)).(*textEncoder)
+			enc.AddBool("enabled", true)
+			assertText(t, `enabled=`+tt.true, enc)
+
+			// Keys are escaped as for other values.
+			key := "a\"b\n"
+			enc.truncate()
+			enc.AddInt(key, 0)
+			escaped := strings.TrimSuffix(enc.buf.String(), "=0")
+			enc.truncate()
+			enc.AddBool(key, false)
+			assertText(t, escaped+`=`+tt.false, enc)
+
+			enc.truncate()
+			assert.NoError(t, enc.AddArray("k", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+				arr.AppendBool(true)
+				arr.AppendBool(false)
+				return nil
+			})))
+			assertText(t, `k=[`+tt.true+`,`+tt.false+`]`, enc)
+		})
+	}
+}
+
+func TestWithBinaryEncoding(t *testing.T) {
+	val := []byte{0xde, 0xad, 0xbe, 0xef, 0xfb, 0xff}
+	tests := []struct {
+		desc     string
+		enc      BinaryEncoding
+		expected string
+		decode   func(string) ([]byte, error)
+	}{
+		{"base64", BinaryBase64, "3q2+7/v/", base64.StdEncoding.DecodeString},
+		{"base64url", BinaryBase64URL, "3q2-7_v_", base64.URLEncoding.DecodeString},
+		{"hex", BinaryHex, "deadbeeffbff", hex.DecodeString},
+		{"hex upper", BinaryHexUpper, "DEADBEEFFBFF", hex.DecodeString},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(zapcore.EncoderConfig{}, WithBinaryEncoding(tt.enc)).(*textEncoder)
+			enc.AddBinary("k", val)
+			assertText(t, `k="`+tt.expected+`"`, enc)
+
+			decoded, err := tt.decode(tt.expected)
+			if assert.NoError(t, err, "Unexpected error decoding binary value.") {
+				assert.Equal(t, val, decoded, "Expected binary value to round-trip.")
+			}
+
+			enc.truncate()
+			assert.NoError(t, enc.AddArray("k", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+				enc.AppendBinary(val)
+				enc.AppendBinary(val)
+				return nil
+			})))
+			assertText(t, `k=["`+tt.expected+`","`+tt.expected+`"]`, enc)
+		})
+	}
+}
+
+func TestWithFloatPrecision(t *testing.T) {
+	tests := []struct {
+		desc      string
+		precision int
+		expected  string
+		f         func(zapcore.Encoder)
+	}{
+		{"shortest", -1, `k=3.14159`, func(e zapcore.Encoder) { e.AddFloat64("k", 3.14159) }},
+		{"two digits", 2, `k=3.14`, func(e zapcore.Encoder) { e.AddFloat64("k", 3.14159) }},
+		{"two digits rounds up", 2, `k=2.00`, func(e zapcore.Encoder) { e.AddFloat64("k", 1.999) }},
+		{"two digits pads", 2, `k=2.50`, func(e zapcore.Encoder) { e.AddFloat64("k", 2.5) }},
+		{"zero digits", 0, `k=4`, func(e zapcore.Encoder) { e.AddFloat64("k", 3.7) }},
+		{"zero digits negative", 0, `k=-4`, func(e zapcore.Encoder) { e.AddFloat64("k", -3.7) }},
+		{"float32", 3, `k=0.100`, func(e zapcore.Encoder) { e.AddFloat32("k", 0.1) }},
+		{"NaN", 2, `k=NaN`, func(e zapcore.Encoder) { e.AddFloat64("k", math.NaN()) }},
+		{"+Inf", 2, `k=+Inf`, func(e zapcore.Encoder) { e.AddFloat64("k", math.Inf(1)) }},
+		{"arrays", 1, `k=[1.0,2.5]`, func(e zapcore.Encoder) {
+			e.AddArray("k", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+				arr.AppendFloat64(1)
+				arr.AppendFloat32(2.49)
+				return nil
+			}))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(zapcore.EncoderConfig{}, WithFloatPrecision(tt.precision)).(*textEncoder)
+			tt.f(enc)
+			assertText(t, tt.expected, enc)
+		})
+	}
+}
+
+func TestWithIntEncoding(t *testing.T) {
+	tests := []struct {
+		desc     string
+		enc      IntEncoding
+		expected string
+		f        func(zapcore.Encoder)
+	}{
+		{"decimal", IntDecimal, `k=255`, func(e zapcore.Encoder) { e.AddInt("k", 255) }},
+		{"hex", IntHex, `k=0xff`, func(e zapcore.Encoder) { e.AddInt("k", 255) }},
+		{"hex zero", IntHex, `k=0x0`, func(e zapcore.Encoder) { e.AddInt64("k", 0) }},
+		{"hex negative", IntHex, `k=-0xff`, func(e zapcore.Encoder) { e.AddInt32("k", -255) }},
+		{"hex min int64", IntHex, `k=-0x8000000000000000`, func(e zapcore.Encoder) { e.AddInt64("k", math.MinInt64) }},
+		{"hex max uint64", IntHex, `k=0xffffffffffffffff`, func(e zapcore.Encoder) { e.AddUint64("k", math.MaxUint64) }},
+		{"hex uint8", IntHex, `k=0xff`, func(e zapcore.Encoder) { e.AddUint8("k", 255) }},
+		{"hex uintptr", IntHex, `k=0xc000`, func(e zapcore.Encoder) { e.AddUintptr("k", 0xc000) }},
+		{"hex upper", IntHexUpper, `k=0xDEADBEEF`, func(e zapcore.Encoder) { e.AddUint32("k", 0xdeadbeef) }},
+		{"octal", IntOctal, `k=0o755`, func(e zapcore.Encoder) { e.AddUint16("k", 0755) }},
+		{"binary", IntBinary, `k=0b101`, func(e zapcore.Encoder) { e.AddInt8("k", 5) }},
+		{"duration fallback", IntHex, `k=255`, func(e zapcore.Encoder) { e.AddDuration("k", 255) }},
+		{"time fallback", IntHex, `k=255`, func(e zapcore.Encoder) { e.AddTime("k", time.Unix(0, 255)) }},
+		{"arrays", IntHex, `k=[0xa,-0x1,0x10]`, func(e zapcore.Encoder) {
+			e.AddArray("k", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+				arr.AppendInt(10)
+				arr.AppendInt64(-1)
+				arr.AppendUint(16)
+				return nil
+			}))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(zapcore.EncoderConfig{}, WithIntEncoding(tt.enc)).(*textEncoder)
+			tt.f(enc)
+			assertText(t, tt.expected, enc)
+		})
+	}
+}
+
+func TestWithMaxFields(t *testing.T) {
+	fields := make([]zapcore.Field, 20)
+	for i := range fields {
+		fields[i] = zap.Int("f"+strconv.Itoa(i), i)
+	}
+
+	assertEncodedEntry(t, "error  lob law  f0=0  f1=1  f2=2  f3=3  f4=4  fields_dropped=15\n",
+		[]Option{WithMaxFields(5)}, fields...)
+	assertEncodedEntry(t, "error  lob law  f0=0  f1=1\n",
+		[]Option{WithMaxFields(2)}, fields[0], zap.Skip(), fields[1])
+	assertEncodedEntry(t, "error  lob law  f0=0  f1=1\n",
+		[]Option{WithMaxFields(0)}, fields[:2]...)
+	assertEncodedEntry(t, "error  lob law  f0=0x0  f1=0x1  fields_dropped=18\n",
+		[]Option{WithMaxFields(2), WithIntEncoding(IntHex)}, fields...)
+}
+
+func TestWithLinePrefix(t *testing.T) {
+	opts := []Option{WithLinePrefix("[APP] ")}
+	assertEncodedEntry(t, "[APP] error  lob law  k=1\n", opts, zap.Int("k", 1))
+	assertEncodedEntry(t, "web | error  lob law\n", []Option{WithLinePrefix("web | ")})
+
+	full := testEncoderConfig()
+	enc := NewTextEncoder(full, WithLinePrefix("[APP\x1b] "))
+	buf, err := enc.EncodeEntry(zapcore.Entry{
+		Level:      zapcore.InfoLevel,
+		Time:       time.Unix(0, 0),
+		LoggerName: "main",
+		Message:    "lob law",
+	}, []zapcore.Field{zap.Int("k", 1)})
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		assert.Equal(t, "[APP\x1b] 0  info   main  lob law  k=1\n", buf.String())
+	}
+	buf.Free()
+
+	bare := NewTextEncoder(zapcore.EncoderConfig{}, WithLinePrefix("[APP] "))
+	buf, err = bare.EncodeEntry(_optionsEntry, []zapcore.Field{zap.Int("k", 1)})
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		assert.Equal(t, "[APP] k=1\n", buf.String(), "Expected no separator after the prefix.")
+	}
+	buf.Free()
+}
+
+func TestWithLineEnding(t *testing.T) {
+	assertEncodedEntry(t, "error  lob law  k=1\r\n", []Option{WithLineEnding(LineEndingCRLF)}, zap.Int("k", 1))
+	assertEncodedEntry(t, "error  lob law  k=1\n", []Option{WithLineEnding(LineEndingLF)}, zap.Int("k", 1))
+	assertEncodedEntry(t, "error  lob law  k=1\n", nil, zap.Int("k", 1))
+
+	cfg := _optionsEncoderConfig
+	cfg.LineEnding = "\n"
+	enc := NewTextEncoder(cfg, WithLineEnding(LineEndingCRLF))
+	buf, err := enc.EncodeEntry(_optionsEntry, nil)
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		assert.Equal(t, "error  lob law\r\n", buf.String(), "Expected the option to override the config.")
+	}
+	buf.Free()
+}
+
+func TestWithNoTrailingNewline(t *testing.T) {
+	assertEncodedEntry(t, "error  lob law  k=1", []Option{WithNoTrailingNewline()}, zap.Int("k", 1))
+	assertEncodedEntry(t, "error  lob law  k=1", []Option{WithNoTrailingNewline(), WithLineEnding(LineEndingCRLF)}, zap.Int("k", 1))
+	assertEncodedEntry(t, "error  lob law  k=1\n", nil, zap.Int("k", 1))
+}
@@ -0,0 +1,74 @@
+package zaptextencoder
+
+import (
+	"sort"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// WithSortFields writes the fields of each entry sorted by key, keeping the
+// relative order of fields with the same key. Fields opened by a
+// zap.Namespace are sorted within it. Fields added by logger.With are
+// written first, in the order they were added.
+func WithSortFields(enabled bool) Option {
+	return func(enc *textEncoder) {
+		enc.sortFields = enabled
+	}
+}
+
+// WithFieldOrder writes the fields of each entry whose key is in priority
+// first, in the order of priority, followed by the others in the order they
+// were given, or sorted by key with WithSortFields. The order applies within
+// each zap.Namespace. Fields added by logger.With are written first, in the
+// order they were added.
+func WithFieldOrder(priority []string) Option {
+	return func(enc *textEncoder) {
+		enc.fieldOrder = make(map[string]int, len(priority))
+		for _, key := range priority {
+			if _, ok := enc.fieldOrder[key]; !ok {
+				enc.fieldOrder[key] = len(enc.fieldOrder)
+			}
+		}
+	}
+}
+
+// fieldRank returns the position of key in the priority list of enc, or the
+// length of the list for keys not in it.
+func (enc *textEncoder) fieldRank(key string) int {
+	if i, ok := enc.fieldOrder[key]; ok {
+		return i
+	}
+	return len(enc.fieldOrder)
+}
+
+// orderFields returns the fields of an entry in the order they are written,
+// sorting a copy if needed so that the slice of the caller is left as is.
+// Namespace fields stay where they are, and only the fields between them are
+// sorted, so that each field is still written inside its namespace.
+func (enc *textEncoder) orderFields(fields []zapcore.Field) []zapcore.Field {
+	if (!enc.sortFields && len(enc.fieldOrder) == 0) || len(fields) < 2 {
+		return fields
+	}
+	sorted := make([]zapcore.Field, len(fields))
+	copy(sorted, fields)
+	start := 0
+	for i := 0; i <= len(sorted); i++ {
+		if i < len(sorted) && sorted[i].Type != zapcore.NamespaceType {
+			continue
+		}
+		enc.sortSegment(sorted[start:i])
+		start = i + 1
+	}
+	return sorted
+}
+
+// sortSegment sorts fields, none of which is a namespace, in place.
+func (enc *textEncoder) sortSegment(fields []zapcore.Field) {
+	sort.SliceStable(fields, func(i, j int) bool {
+		ri, rj := enc.fieldRank(fields[i].Key), enc.fieldRank(fields[j].Key)
+		if ri != rj {
+			return ri < rj
+		}
+		return enc.sortFields && fields[i].Key < fields[j].Key
+	})
+}
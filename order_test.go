@@ -0,0 +1,60 @@
+package zaptextencoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithSortFields(t *testing.T) {
+	fields := []zapcore.Field{
+		zap.Int("zebra", 1),
+		zap.Int("yak", 2),
+		zap.Int("mole", 3),
+		zap.Int("yak", 4),
+		zap.Int("aardvark", 5),
+	}
+	original := append([]zapcore.Field(nil), fields...)
+
+	assertEncodedEntry(t, "error  lob law  aardvark=5  mole=3  yak=2  yak=4  zebra=1\n",
+		[]Option{WithSortFields(true)}, fields...)
+	assert.Equal(t, original, fields, "Expected the fields of the caller to be left as is.")
+
+	assertEncodedEntry(t, "error  lob law  zebra=1  yak=2  mole=3  yak=4  aardvark=5\n",
+		[]Option{WithSortFields(false)}, fields...)
+}
+
+func TestWithSortFieldsNamespace(t *testing.T) {
+	assertEncodedEntry(t, "error  lob law  b=\"1\"  ns.a=\"2\"\n",
+		[]Option{WithSortFields(true)},
+		zap.String("b", "1"), zap.Namespace("ns"), zap.String("a", "2"))
+	assertEncodedEntry(t, "error  lob law  a=1  c=3  ns.b=2  ns.d=4\n",
+		[]Option{WithSortFields(true)},
+		zap.Int("c", 3), zap.Int("a", 1), zap.Namespace("ns"), zap.Int("d", 4), zap.Int("b", 2))
+}
+
+func TestWithFieldOrder(t *testing.T) {
+	fields := []zapcore.Field{
+		zap.Int("zebra", 1),
+		zap.Int("aardvark", 2),
+		zap.String("user_id", "u"),
+		zap.String("trace_id", "t"),
+	}
+	priority := []string{"trace_id", "user_id", "missing"}
+
+	assertEncodedEntry(t, "error  lob law  trace_id=\"t\"  user_id=\"u\"  zebra=1  aardvark=2\n",
+		[]Option{WithFieldOrder(priority)}, fields...)
+	assertEncodedEntry(t, "error  lob law  trace_id=\"t\"  user_id=\"u\"  aardvark=2  zebra=1\n",
+		[]Option{WithFieldOrder(priority), WithSortFields(true)}, fields...)
+}
+
+func TestWithFieldOrderNamespace(t *testing.T) {
+	assertEncodedEntry(t, "error  lob law  b=1  ns.a=2\n",
+		[]Option{WithFieldOrder([]string{"a"})},
+		zap.Int("b", 1), zap.Namespace("ns"), zap.Int("a", 2))
+	assertEncodedEntry(t, "error  lob law  a=1  b=2  ns.a=4  ns.c=3\n",
+		[]Option{WithFieldOrder([]string{"a"})},
+		zap.Int("b", 2), zap.Int("a", 1), zap.Namespace("ns"), zap.Int("c", 3), zap.Int("a", 4))
+}
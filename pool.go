@@ -0,0 +1,117 @@
+package zaptextencoder
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/buffer"
+)
+
+var bufferPool = newBufferPool(0)
+
+// textBufferPool hands out buffers able to hold at least initialSize bytes
+// without reallocating, and keeps statistics about them, see
+// GetBufferPoolStats. Buffers given back with Put are kept in sized, while
+// those freed by callers go back to the buffer.Pool its New func takes
+// buffers from, growing them once if they are too small.
+type textBufferPool struct {
+	// The counters come first to keep them 64-bit aligned for atomic
+	// operations on 32-bit platforms.
+	gets, puts, peak int64
+
+	sized sync.Pool
+}
+
+func newBufferPool(initialSize int) *textBufferPool {
+	p := &textBufferPool{}
+	pool := buffer.NewPool()
+	// Writing zeros of the full size grows a buffer with a single
+	// allocation, sharing the zeros between all buffers.
+	zeros := make([]byte, initialSize)
+	p.sized.New = func() interface{} {
+		buf := pool.Get()
+		if buf.Cap() < initialSize {
+			_, _ = buf.Write(zeros)
+			buf.Reset()
+		}
+		return buf
+	}
+	return p
+}
+
+// Get retrieves a buffer from the pool, creating one if necessary.
+func (p *textBufferPool) Get() *buffer.Buffer {
+	buf := p.sized.Get().(*buffer.Buffer)
+	gets := atomic.AddInt64(&p.gets, 1)
+	inUse := gets - atomic.LoadInt64(&p.puts)
+	for {
+		peak := atomic.LoadInt64(&p.peak)
+		if inUse <= peak || atomic.CompareAndSwapInt64(&p.peak, peak, inUse) {
+			break
+		}
+	}
+	return buf
+}
+
+// getKept retrieves a buffer for an encoder to keep as long as it lives,
+// such as those of NewTextEncoder and Clone. It is left to the garbage
+// collector with the encoder, and not counted in the statistics.
+func (p *textBufferPool) getKept() *buffer.Buffer {
+	return p.sized.Get().(*buffer.Buffer)
+}
+
+// Put returns buf to the pool.
+func (p *textBufferPool) Put(buf *buffer.Buffer) {
+	p.handOff()
+	buf.Reset()
+	p.sized.Put(buf)
+}
+
+// handOff counts a buffer as returned to the pool when it is handed to a
+// caller, which frees it itself.
+func (p *textBufferPool) handOff() {
+	atomic.AddInt64(&p.puts, 1)
+}
+
+// BufferPoolStats is a snapshot of the use of the buffers text encoders
+// encode entries with. The buffers encoders keep for the fields added to
+// them, by NewTextEncoderWithFields or logger.With, are not counted.
+type BufferPoolStats struct {
+	// TotalGet counts the buffers taken from the pool.
+	TotalGet int64
+	// TotalPut counts the buffers given back. The buffer of an entry
+	// counts as given back once EncodeEntry returns it, since its caller
+	// frees it with Buffer.Free.
+	TotalPut int64
+	// CurrentInUse is TotalGet - TotalPut, the buffers of the entries
+	// being encoded, back to zero once none are.
+	CurrentInUse int64
+	// PeakInUse is the highest CurrentInUse seen.
+	PeakInUse int64
+}
+
+// GetBufferPoolStats returns a snapshot of the statistics of the buffer
+// pool, since the program started or SetBufferPoolInitialSize was called.
+func GetBufferPoolStats() BufferPoolStats {
+	p := bufferPool
+	puts := atomic.LoadInt64(&p.puts)
+	gets := atomic.LoadInt64(&p.gets)
+	return BufferPoolStats{
+		TotalGet:     gets,
+		TotalPut:     puts,
+		CurrentInUse: gets - puts,
+		PeakInUse:    atomic.LoadInt64(&p.peak),
+	}
+}
+
+// SetBufferPoolInitialSize replaces the pool of buffers used by all text
+// encoders with one whose buffers can hold at least n bytes when they are
+// created, saving the allocations of growing them for large entries. Sizes
+// below that of zap's buffers, 1KiB, have no effect. The statistics of the
+// pool are reset.
+//
+// It is not safe to call concurrently with logging, and is meant to be
+// called once, before any encoder is used.
+func SetBufferPoolInitialSize(n int) {
+	bufferPool = newBufferPool(n)
+}
@@ -0,0 +1,118 @@
+package zaptextencoder
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSetBufferPoolInitialSize(t *testing.T) {
+	defer SetBufferPoolInitialSize(0)
+
+	SetBufferPoolInitialSize(8 << 10)
+	buf := bufferPool.Get()
+	assert.True(t, buf.Cap() >= 8<<10, "Expected a buffer of at least 8KiB, got %d.", buf.Cap())
+	assert.Equal(t, 0, buf.Len(), "Expected an empty buffer.")
+	buf.Free()
+
+	enc := NewTextEncoder(_optionsEncoderConfig)
+	out, err := enc.EncodeEntry(_optionsEntry, []zap.Field{zap.Int("answer", 42)})
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		assert.True(t, out.Cap() >= 8<<10, "Expected entries to use the new pool.")
+		assert.Equal(t, "error  lob law  answer=42\n", out.String())
+	}
+	out.Free()
+}
+
+func TestGetBufferPoolStats(t *testing.T) {
+	defer SetBufferPoolInitialSize(0)
+	SetBufferPoolInitialSize(0)
+
+	const n = 5
+	bufs := make([]*buffer.Buffer, n)
+	for i := range bufs {
+		bufs[i] = bufferPool.Get()
+	}
+	assert.Equal(t, BufferPoolStats{TotalGet: n, CurrentInUse: n, PeakInUse: n}, GetBufferPoolStats())
+
+	for _, buf := range bufs {
+		bufferPool.Put(buf)
+	}
+	assert.Equal(t, BufferPoolStats{TotalGet: n, TotalPut: n, PeakInUse: n}, GetBufferPoolStats())
+
+	enc := NewTextEncoder(_optionsEncoderConfig)
+	assert.Equal(t, BufferPoolStats{TotalGet: n, TotalPut: n, PeakInUse: n}, GetBufferPoolStats(),
+		"Expected the buffer of the encoder not to be counted.")
+	out, err := enc.EncodeEntry(_optionsEntry, nil)
+	assert.NoError(t, err, "Unexpected text encoding error.")
+	out.Free()
+	assert.Equal(t, BufferPoolStats{TotalGet: n + 1, TotalPut: n + 1, PeakInUse: n}, GetBufferPoolStats())
+}
+
+// failingEncoder fails to encode entries.
+type failingEncoder struct{ zapcore.Encoder }
+
+func (failingEncoder) EncodeEntry(zapcore.Entry, []zapcore.Field) (*buffer.Buffer, error) {
+	return nil, errors.New("fail")
+}
+
+func TestGetBufferPoolStatsLoggers(t *testing.T) {
+	defer SetBufferPoolInitialSize(0)
+	SetBufferPoolInitialSize(0)
+
+	tests := []struct {
+		desc   string
+		enc    zapcore.Encoder
+		with   []zap.Field
+		fields []zap.Field
+	}{
+		{
+			desc:   "text",
+			enc:    NewTextEncoderWithFields(_optionsEncoderConfig, zap.String("service", "api")),
+			with:   []zap.Field{zap.Int("attempt", 1)},
+			fields: []zap.Field{zap.Ints("ids", []int{1, 2}), zap.Object("obj", event{"start", 1})},
+		},
+		{
+			desc:   "logfmt",
+			enc:    NewLogfmtEncoder(_optionsEncoderConfig),
+			fields: []zap.Field{zap.Ints("ids", []int{1, 2})},
+		},
+		{
+			desc:   "conditional",
+			enc:    NewTextEncoder(_optionsEncoderConfig),
+			with:   []zap.Field{ConditionalField(zap.InfoLevel, zap.String("body", "..."))},
+			fields: []zap.Field{zap.Int("answer", 42)},
+		},
+		{
+			desc:   "GELF",
+			enc:    NewGELFEncoder(_optionsEncoderConfig, "db-1"),
+			fields: []zap.Field{zap.Int("answer", 42)},
+		},
+		{
+			desc: "GELF error",
+			enc: &gelfEncoder{
+				Encoder: failingEncoder{zapcore.NewJSONEncoder(zapcore.EncoderConfig{})},
+				cfg:     &zapcore.EncoderConfig{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			core := zapcore.NewCore(tt.enc, zapcore.AddSync(ioutil.Discard), zap.DebugLevel)
+			logger := zap.New(core, zap.ErrorOutput(zapcore.AddSync(ioutil.Discard))).With(tt.with...)
+			before := GetBufferPoolStats()
+			for i := 0; i < 3; i++ {
+				logger.Info("lob law", tt.fields...)
+				stats := GetBufferPoolStats()
+				assert.Equal(t, int64(0), stats.CurrentInUse, "Expected every buffer to be given back after entry %d.", i)
+				assert.True(t, stats.TotalGet > before.TotalGet, "Expected entries to take buffers from the pool.")
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package zaptextencoder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"pgregory.net/rapid"
+)
+
+// anyString generates strings which may hold invalid UTF-8.
+var anyString = rapid.OneOf(
+	rapid.String(),
+	rapid.Custom(func(t *rapid.T) string {
+		return string(rapid.SliceOf(rapid.Byte()).Draw(t, "bytes").([]byte))
+	}),
+)
+
+func fieldGen(depth int) *rapid.Generator {
+	return rapid.Custom(func(t *rapid.T) zapcore.Field {
+		key := anyString.Draw(t, "key").(string)
+		kinds := 10
+		if depth > 0 {
+			kinds = 12
+		}
+		switch rapid.IntRange(0, kinds-1).Draw(t, "kind").(int) {
+		case 0:
+			return zap.String(key, anyString.Draw(t, "string").(string))
+		case 1:
+			return zap.ByteString(key, []byte(anyString.Draw(t, "bytes").(string)))
+		case 2:
+			return zap.Int64(key, rapid.Int64().Draw(t, "int64").(int64))
+		case 3:
+			return zap.Float64(key, rapid.Float64().Draw(t, "float64").(float64))
+		case 4:
+			return zap.Bool(key, rapid.Bool().Draw(t, "bool").(bool))
+		case 5:
+			return zap.Binary(key, rapid.SliceOf(rapid.Byte()).Draw(t, "binary").([]byte))
+		case 6:
+			return zap.Duration(key, time.Duration(rapid.Int64().Draw(t, "duration").(int64)))
+		case 7:
+			return zap.NamedError(key, errors.New(anyString.Draw(t, "error").(string)))
+		case 8:
+			return zap.Reflect(key, rapid.MapOf(anyString, anyString).Draw(t, "reflect"))
+		case 9:
+			return zap.Strings(key, rapid.SliceOf(anyString).Draw(t, "strings").([]string))
+		case 10:
+			fields := rapid.SliceOfN(fieldGen(depth-1), 0, 4).Draw(t, "object").([]zapcore.Field)
+			return zap.Object(key, zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+				for _, f := range fields {
+					f.AddTo(enc)
+				}
+				return nil
+			}))
+		default:
+			fields := rapid.SliceOfN(fieldGen(depth-1), 0, 4).Draw(t, "array").([]zapcore.Field)
+			return zap.Array(key, zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+				for _, f := range fields {
+					if err := enc.AppendObject(zapcore.ObjectMarshalerFunc(func(obj zapcore.ObjectEncoder) error {
+						f.AddTo(obj)
+						return nil
+					})); err != nil {
+						return err
+					}
+				}
+				return nil
+			}))
+		}
+	})
+}
+
+func TestEncodeEntryProperties(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		ent := zapcore.Entry{
+			Level:      zapcore.Level(rapid.IntRange(int(zapcore.DebugLevel), int(zapcore.FatalLevel)).Draw(t, "level").(int)),
+			Time:       time.Unix(0, rapid.Int64().Draw(t, "time").(int64)),
+			LoggerName: anyString.Draw(t, "name").(string),
+			Message:    anyString.Draw(t, "message").(string),
+		}
+		fields := rapid.SliceOfN(fieldGen(2), 0, 8).Draw(t, "fields").([]zapcore.Field)
+
+		enc := NewTextEncoder(testEncoderConfig())
+		buf, err := enc.EncodeEntry(ent, fields)
+		if err != nil {
+			t.Fatalf("Unexpected encoding error: %v", err)
+		}
+		out := buf.String()
+		buf.Free()
+
+		if !utf8.ValidString(out) {
+			t.Fatalf("Output isn't valid UTF-8: %q", out)
+		}
+		if !strings.HasSuffix(out, "\n") || strings.Count(out, "\n") != 1 {
+			t.Fatalf("Output doesn't end with exactly one newline: %q", out)
+		}
+		if strings.IndexByte(out, 0) >= 0 {
+			t.Fatalf("Output holds a NUL byte: %q", out)
+		}
+	})
+}
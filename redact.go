@@ -0,0 +1,106 @@
+package zaptextencoder
+
+import (
+	"path"
+	"regexp"
+)
+
+// WithSensitiveKeys replaces the value of every field whose key matches one
+// of patterns with a mask, see WithSensitiveKeyMask. A pattern matches a key
+// exactly, or as a glob in the syntax of path.Match, such as "*_token".
+// Fields of any type are masked, at any depth in nested objects.
+func WithSensitiveKeys(patterns []string) Option {
+	return func(enc *textEncoder) {
+		enc.sensitiveKeys = newSensitiveKeys(patterns)
+	}
+}
+
+// WithSensitiveKeyMask sets the string written in place of the value of a
+// sensitive field. The default is "[REDACTED]".
+func WithSensitiveKeyMask(mask string) Option {
+	return func(enc *textEncoder) {
+		enc.mask = mask
+	}
+}
+
+// sensitiveKeys is a compiled list of key patterns.
+type sensitiveKeys struct {
+	exact map[string]struct{}
+	globs []string
+}
+
+func newSensitiveKeys(patterns []string) sensitiveKeys {
+	var keys sensitiveKeys
+	for _, p := range patterns {
+		if isGlob(p) {
+			keys.globs = append(keys.globs, p)
+			continue
+		}
+		if keys.exact == nil {
+			keys.exact = make(map[string]struct{}, len(patterns))
+		}
+		keys.exact[p] = struct{}{}
+	}
+	return keys
+}
+
+// isGlob reports whether p is a valid pattern using glob syntax. Invalid
+// patterns are matched exactly.
+func isGlob(p string) bool {
+	for i := 0; i < len(p); i++ {
+		switch p[i] {
+		case '*', '?', '[', '\\':
+			_, err := path.Match(p, "")
+			return err == nil
+		}
+	}
+	return false
+}
+
+func (keys sensitiveKeys) match(key string) bool {
+	if _, ok := keys.exact[key]; ok {
+		return true
+	}
+	for _, p := range keys.globs {
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactionRule replaces every match of Pattern in a string value with
+// Replacement, in which $1 and ${name} are expanded as in
+// regexp.Regexp.ReplaceAllString.
+type RedactionRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// WithValueRedactions applies rules in order to every string value, after
+// it is truncated and before it is escaped and quoted, whatever its key.
+// Keys are never redacted.
+func WithValueRedactions(rules []RedactionRule) Option {
+	return func(enc *textEncoder) {
+		enc.redactions = make([]RedactionRule, 0, len(rules))
+		for _, r := range rules {
+			if r.Pattern != nil {
+				enc.redactions = append(enc.redactions, r)
+			}
+		}
+	}
+}
+
+func (enc *textEncoder) redactString(s string) string {
+	for _, r := range enc.redactions {
+		s = r.Pattern.ReplaceAllString(s, r.Replacement)
+	}
+	return s
+}
+
+func (enc *textEncoder) redactByteString(b []byte) []byte {
+	for _, r := range enc.redactions {
+		b = r.Pattern.ReplaceAll(b, []byte(r.Replacement))
+	}
+	return b
+}
@@ -0,0 +1,146 @@
+package zaptextencoder
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithSensitiveKeys(t *testing.T) {
+	tests := []struct {
+		desc     string
+		opts     []Option
+		fields   []zapcore.Field
+		expected string
+	}{
+		{
+			desc:     "exact match",
+			opts:     []Option{WithSensitiveKeys([]string{"password"})},
+			fields:   []zapcore.Field{zap.String("password", "hunter2"), zap.String("passwordhash", "abc")},
+			expected: "error  lob law  password=\"[REDACTED]\"  passwordhash=\"abc\"\n",
+		},
+		{
+			desc:     "glob",
+			opts:     []Option{WithSensitiveKeys([]string{"*_token", "secret?"})},
+			fields:   []zapcore.Field{zap.String("api_token", "t"), zap.String("secret1", "s"), zap.String("token", "u")},
+			expected: "error  lob law  api_token=\"[REDACTED]\"  secret1=\"[REDACTED]\"  token=\"u\"\n",
+		},
+		{
+			desc: "any type",
+			opts: []Option{WithSensitiveKeys([]string{"k"})},
+			fields: []zapcore.Field{
+				zap.Int("k", 1),
+				zap.Bool("k", true),
+				zap.Float64("k", 1.5),
+				zap.Duration("k", time.Second),
+				zap.ByteString("k", []byte("b")),
+				zap.Binary("k", []byte("b")),
+				zap.Strings("k", []string{"a"}),
+				zap.Reflect("k", map[string]int{"a": 1}),
+				zap.Error(errors.New("boom")),
+			},
+			expected: "error  lob law  k=\"[REDACTED]\"  k=\"[REDACTED]\"  k=\"[REDACTED]\"  k=\"[REDACTED]\"  k=\"[REDACTED]\"  k=\"[REDACTED]\"  k=\"[REDACTED]\"  k=\"[REDACTED]\"  error=\"boom\"\n",
+		},
+		{
+			desc:     "error key",
+			opts:     []Option{WithSensitiveKeys([]string{"error"})},
+			fields:   []zapcore.Field{zap.Error(errors.New("boom"))},
+			expected: "error  lob law  error=\"[REDACTED]\"\n",
+		},
+		{
+			desc:     "nested",
+			opts:     []Option{WithSensitiveKeys([]string{"password"})},
+			fields:   []zapcore.Field{zap.Object("user", loginUser{"bob", "hunter2"})},
+			expected: "error  lob law  user={name=\"bob\"  password=\"[REDACTED]\"}\n",
+		},
+		{
+			desc:     "custom mask",
+			opts:     []Option{WithSensitiveKeys([]string{"password"}), WithSensitiveKeyMask("***")},
+			fields:   []zapcore.Field{zap.String("password", "hunter2")},
+			expected: "error  lob law  password=\"***\"\n",
+		},
+		{
+			desc:     "invalid glob matches exactly",
+			opts:     []Option{WithSensitiveKeys([]string{"[a"})},
+			fields:   []zapcore.Field{zap.String("[a", "x"), zap.String("a", "y")},
+			expected: "error  lob law  [a=\"[REDACTED]\"  a=\"y\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assertEncodedEntry(t, tt.expected, tt.opts, tt.fields...)
+		})
+	}
+}
+
+type loginUser struct {
+	name     string
+	password string
+}
+
+func (u loginUser) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", u.name)
+	enc.AddString("password", u.password)
+	return nil
+}
+
+func TestWithValueRedactions(t *testing.T) {
+	card := RedactionRule{regexp.MustCompile(`\d{16}`), "****-****-****-****"}
+	tests := []struct {
+		desc     string
+		rules    []RedactionRule
+		fields   []zapcore.Field
+		expected string
+	}{
+		{
+			desc:     "string",
+			rules:    []RedactionRule{card},
+			fields:   []zapcore.Field{zap.String("card", "paid with 4111111111111111")},
+			expected: "error  lob law  card=\"paid with ****-****-****-****\"\n",
+		},
+		{
+			desc:     "byte string",
+			rules:    []RedactionRule{card},
+			fields:   []zapcore.Field{zap.ByteString("card", []byte("4111111111111111"))},
+			expected: "error  lob law  card=\"****-****-****-****\"\n",
+		},
+		{
+			desc:     "keys untouched",
+			rules:    []RedactionRule{card},
+			fields:   []zapcore.Field{zap.Int("4111111111111111", 1)},
+			expected: "error  lob law  4111111111111111=1\n",
+		},
+		{
+			desc: "in order",
+			rules: []RedactionRule{
+				{regexp.MustCompile(`secret`), "hidden"},
+				{regexp.MustCompile(`hidden`), "gone"},
+			},
+			fields:   []zapcore.Field{zap.String("k", "secret")},
+			expected: "error  lob law  k=\"gone\"\n",
+		},
+		{
+			desc:     "expansion",
+			rules:    []RedactionRule{{regexp.MustCompile(`(\w+)@\w+\.com`), "$1@***"}},
+			fields:   []zapcore.Field{zap.String("k", "bob@example.com")},
+			expected: "error  lob law  k=\"bob@***\"\n",
+		},
+		{
+			desc:     "replacement escaped",
+			rules:    []RedactionRule{{card.Pattern, "\"redacted\"\n"}},
+			fields:   []zapcore.Field{zap.String("k", "4111111111111111")},
+			expected: "error  lob law  k=\"\\\"redacted\\\"\\n\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assertEncodedEntry(t, tt.expected, []Option{WithValueRedactions(tt.rules)}, tt.fields...)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package zaptextencoder
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// WithPrettyReflect writes fields added with zap.Reflect as indented JSON
+// spanning several lines, see WithReflectIndent. Every line after the first
+// is indented by the width of the key and its delimiter, so that the JSON
+// lines up.
+func WithPrettyReflect(enabled bool) Option {
+	return func(enc *textEncoder) {
+		enc.prettyReflect = enabled
+	}
+}
+
+// WithReflectIndent sets the string used for each level of indentation by
+// WithPrettyReflect. The default is two spaces.
+func WithReflectIndent(indent string) Option {
+	return func(enc *textEncoder) {
+		enc.reflectIndent = indent
+	}
+}
+
+func (enc *textEncoder) addPrettyReflected(key string, obj interface{}) error {
+	indent := enc.reflectIndent
+	if indent == "" {
+		indent = "  "
+	}
+	width := utf8.RuneCountInString(enc.namespacedKey(key) + enc.keyDelimiter())
+	compact, err := enc.marshalJSON(obj)
+	if err != nil {
+		return err
+	}
+	var marshaled bytes.Buffer
+	if err := json.Indent(&marshaled, compact, strings.Repeat(" ", width), indent); err != nil {
+		return err
+	}
+	if !enc.addKey(key) {
+		return nil
+	}
+	_, err = enc.buf.Write(marshaled.Bytes())
+	enc.closeKey(key)
+	return err
+}
+
+// WithJSONMarshaler replaces json.Marshal for the values of fields added
+// with zap.Reflect or zap.Any, such as with the Marshal function of a
+// faster JSON package. Its errors are returned as those of json.Marshal.
+// The order of map keys is then up to marshal.
+func WithJSONMarshaler(marshal func(v interface{}) ([]byte, error)) Option {
+	return func(enc *textEncoder) {
+		enc.jsonMarshaler = marshal
+	}
+}
+
+func (enc *textEncoder) marshalJSON(v interface{}) ([]byte, error) {
+	if enc.jsonMarshaler != nil {
+		return enc.jsonMarshaler(v)
+	}
+	return json.Marshal(v)
+}
+
+// TypeEncoder writes v, a value of the type it is registered for, to enc.
+type TypeEncoder func(v interface{}, enc zapcore.PrimitiveArrayEncoder) error
+
+// TypeEncoderRegistry maps types to the TypeEncoder writing the values of
+// fields added with zap.Reflect or zap.Any instead of encoding/json. A type
+// is looked up as is, then among the interface types of the registry it
+// implements, in no particular order.
+type TypeEncoderRegistry map[reflect.Type]TypeEncoder
+
+// WithTypeEncoders sets the registry of encoders for reflected values.
+func WithTypeEncoders(registry TypeEncoderRegistry) Option {
+	return func(enc *textEncoder) {
+		enc.typeEncoders = registry
+	}
+}
+
+// lookup returns the TypeEncoder for the dynamic type of v, or nil.
+func (r TypeEncoderRegistry) lookup(v interface{}) TypeEncoder {
+	if len(r) == 0 || v == nil {
+		return nil
+	}
+	t := reflect.TypeOf(v)
+	if fn, ok := r[t]; ok {
+		return fn
+	}
+	for rt, fn := range r {
+		if rt.Kind() == reflect.Interface && t.Implements(rt) {
+			return fn
+		}
+	}
+	return nil
+}
+
+// WithPreferStringerInReflect writes values of fields added with
+// zap.Reflect or zap.Any that implement fmt.Stringer as the string returned
+// by their String method rather than as JSON, unless they also implement
+// json.Marshaler.
+func WithPreferStringerInReflect(enabled bool) Option {
+	return func(enc *textEncoder) {
+		enc.preferStringer = enabled
+	}
+}
+
+// WithPreferTextMarshalerInReflect writes values of fields added with
+// zap.Reflect or zap.Any that implement encoding.TextMarshaler as the text
+// returned by their MarshalText method rather than as JSON, such as
+// time.Time in RFC 3339 or big.Int in decimal. It takes precedence over
+// WithPreferStringerInReflect and json.Marshaler; values failing to marshal
+// as text are written as JSON.
+func WithPreferTextMarshalerInReflect(enabled bool) Option {
+	return func(enc *textEncoder) {
+		enc.preferTextMarshaler = enabled
+	}
+}
+
+// reflectedText returns the text a reflected value is written as instead
+// of JSON, if any. As in zap, a MarshalText or String method panicking on a
+// nil receiver gives "<nil>", and any other panic is returned as an error.
+func (enc *textEncoder) reflectedText(v interface{}) (text string, ok bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+				text, ok, err = "<nil>", true, nil
+				return
+			}
+			text, ok, err = "", false, fmt.Errorf("PANIC=%v", r)
+		}
+	}()
+	if enc.preferTextMarshaler {
+		if m, ok := v.(encoding.TextMarshaler); ok {
+			if text, err := m.MarshalText(); err == nil {
+				return string(text), true, nil
+			}
+		}
+	}
+	if enc.preferStringer {
+		if s, ok := v.(fmt.Stringer); ok {
+			if _, ok := v.(json.Marshaler); !ok {
+				return s.String(), true, nil
+			}
+		}
+	}
+	return "", false, nil
+}
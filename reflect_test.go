@@ -0,0 +1,259 @@
+package zaptextencoder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type reflectInner struct {
+	Name string `json:"name"`
+}
+
+type reflectOuter struct {
+	ID    int          `json:"id"`
+	Inner reflectInner `json:"inner"`
+}
+
+func TestWithPrettyReflect(t *testing.T) {
+	obj := reflectOuter{ID: 1, Inner: reflectInner{Name: "x"}}
+	tests := []struct {
+		desc     string
+		opts     []Option
+		expected string
+	}{
+		{
+			desc: "pretty",
+			opts: []Option{WithPrettyReflect(true)},
+			expected: "error  lob law  obj={\n" +
+				"      \"id\": 1,\n" +
+				"      \"inner\": {\n" +
+				"        \"name\": \"x\"\n" +
+				"      }\n" +
+				"    }\n",
+		},
+		{
+			desc: "custom indent",
+			opts: []Option{WithPrettyReflect(true), WithReflectIndent("\t")},
+			expected: "error  lob law  obj={\n" +
+				"    \t\"id\": 1,\n" +
+				"    \t\"inner\": {\n" +
+				"    \t\t\"name\": \"x\"\n" +
+				"    \t}\n" +
+				"    }\n",
+		},
+		{
+			desc:     "disabled",
+			expected: "error  lob law  obj={\"id\":1,\"inner\":{\"name\":\"x\"}}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assertEncodedEntry(t, tt.expected, tt.opts, zap.Reflect("obj", obj))
+		})
+	}
+}
+
+func TestWithPrettyReflectContext(t *testing.T) {
+	obj := reflectOuter{ID: 1, Inner: reflectInner{Name: "x"}}
+	for _, tt := range []struct {
+		desc string
+		opts []Option
+	}{
+		{"context", nil},
+		{"deduplicated context", []Option{WithDeduplicateStrategy(DeduplicateLastWins)}},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(_optionsEncoderConfig, append(tt.opts, WithPrettyReflect(true))...)
+			enc.AddReflected("obj", obj)
+			ent := _optionsEntry
+			ent.Message = "lob\nlaw"
+			buf, err := enc.EncodeEntry(ent, nil)
+			if assert.NoError(t, err, "Unexpected text encoding error.") {
+				assert.Equal(t, "error  obj={\n"+
+					"      \"id\": 1,\n"+
+					"      \"inner\": {\n"+
+					"        \"name\": \"x\"\n"+
+					"      }\n"+
+					"    }  lob\\nlaw\n", buf.String(),
+					"Expected the fields added to the encoder as encoded, and the message escaped.")
+				buf.Free()
+			}
+		})
+	}
+}
+
+type celsius float64
+
+func (c celsius) String() string { return fmt.Sprintf("%.1f°C", float64(c)) }
+
+func TestWithTypeEncoders(t *testing.T) {
+	registry := TypeEncoderRegistry{
+		reflect.TypeOf(time.Time{}): func(v interface{}, enc zapcore.PrimitiveArrayEncoder) error {
+			enc.AppendString(v.(time.Time).Format(time.RFC3339))
+			return nil
+		},
+		reflect.TypeOf((*fmt.Stringer)(nil)).Elem(): func(v interface{}, enc zapcore.PrimitiveArrayEncoder) error {
+			enc.AppendString(v.(fmt.Stringer).String())
+			return nil
+		},
+		reflect.TypeOf(0): func(v interface{}, enc zapcore.PrimitiveArrayEncoder) error {
+			return errors.New("no ints")
+		},
+	}
+	ts := time.Date(2018, 6, 19, 16, 33, 42, 99, time.UTC)
+	opts := []Option{WithTypeEncoders(registry)}
+
+	assertEncodedEntry(t, "error  lob law  ts=\"2018-06-19T16:33:42Z\"\n", opts, zap.Reflect("ts", ts))
+	assertEncodedEntry(t, "error  lob law  ts=\"2018-06-19T16:33:42.000000099Z\"\n", nil, zap.Reflect("ts", ts))
+	assertEncodedEntry(t, "error  lob law  temp=\"21.5°C\"\n", opts, zap.Reflect("temp", celsius(21.5)))
+	assertEncodedEntry(t, "error  lob law  list=[\"2018-06-19T16:33:42Z\",1.5]\n", opts,
+		zap.Array("list", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+			if err := enc.AppendReflected(ts); err != nil {
+				return err
+			}
+			return enc.AppendReflected(1.5)
+		})))
+	assertEncodedEntry(t, "error  lob law  nil=null\n", opts, zap.Reflect("nil", nil))
+
+	enc := NewTextEncoder(_optionsEncoderConfig, opts...)
+	assert.EqualError(t, enc.AddReflected("n", 1), "no ints")
+}
+
+func TestReflectedMapKeysSorted(t *testing.T) {
+	// encoding/json sorts map keys, so reflected maps are deterministic
+	// without any option.
+	m := map[string]interface{}{"b": 2, "a": 1, "c": map[string]int{"z": 26, "y": 25}}
+	for i := 0; i < 20; i++ {
+		assertEncodedEntry(t, "error  lob law  m={\"a\":1,\"b\":2,\"c\":{\"y\":25,\"z\":26}}\n", nil, zap.Reflect("m", m))
+	}
+	assertEncodedEntry(t, "error  lob law  m={\n    \"a\": 1,\n    \"b\": 2,\n    \"c\": {\n      \"y\": 25,\n      \"z\": 26\n    }\n  }\n",
+		[]Option{WithPrettyReflect(true)}, zap.Reflect("m", m))
+}
+
+type opaque struct {
+	id int
+}
+
+func (o opaque) String() string { return fmt.Sprintf("opaque#%d", o.id) }
+
+type both struct {
+	id int
+}
+
+func (b both) String() string               { return fmt.Sprintf("both#%d", b.id) }
+func (b both) MarshalJSON() ([]byte, error) { return []byte(fmt.Sprintf(`{"id":%d}`, b.id)), nil }
+
+func TestWithPreferStringerInReflect(t *testing.T) {
+	opts := []Option{WithPreferStringerInReflect(true)}
+	assertEncodedEntry(t, "error  lob law  o=\"opaque#1\"\n", opts, zap.Reflect("o", opaque{1}))
+	assertEncodedEntry(t, "error  lob law  o={}\n", nil, zap.Reflect("o", opaque{1}))
+	assertEncodedEntry(t, "error  lob law  b={\"id\":2}\n", opts, zap.Reflect("b", both{2}))
+	assertEncodedEntry(t, "error  lob law  b={\"id\":2}\n", nil, zap.Reflect("b", both{2}))
+	assertEncodedEntry(t, "error  lob law  list=[\"opaque#1\",{\"id\":2}]\n", opts,
+		zap.Array("list", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+			if err := enc.AppendReflected(opaque{1}); err != nil {
+				return err
+			}
+			return enc.AppendReflected(both{2})
+		})))
+}
+
+type nilStringer struct {
+	name string
+}
+
+func (s *nilStringer) String() string { return s.name }
+
+func (s *nilStringer) MarshalText() ([]byte, error) { return []byte(s.name), nil }
+
+type panicStringer struct{}
+
+func (panicStringer) String() string { panic("boom") }
+
+func TestReflectedTextPanics(t *testing.T) {
+	for _, opt := range []Option{WithPreferStringerInReflect(true), WithPreferTextMarshalerInReflect(true)} {
+		assertEncodedEntry(t, "error  lob law  s=\"<nil>\"\n", []Option{opt}, zap.Reflect("s", (*nilStringer)(nil)))
+		assertEncodedEntry(t, "error  lob law  list=[\"<nil>\"]\n", []Option{opt},
+			zap.Array("list", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+				return enc.AppendReflected((*nilStringer)(nil))
+			})))
+	}
+
+	enc := NewTextEncoder(_optionsEncoderConfig, WithPreferStringerInReflect(true))
+	assert.EqualError(t, enc.AddReflected("p", panicStringer{}), "PANIC=boom")
+}
+
+func TestWithPreferTextMarshalerInReflect(t *testing.T) {
+	ts := time.Date(2018, 6, 19, 16, 33, 42, 99, time.UTC)
+	n, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	opts := []Option{WithPreferTextMarshalerInReflect(true)}
+
+	assertEncodedEntry(t, "error  lob law  ts=\"2018-06-19T16:33:42.000000099Z\"\n", opts, zap.Reflect("ts", ts))
+	assertEncodedEntry(t, "error  lob law  n=\"123456789012345678901234567890\"\n", opts, zap.Reflect("n", n))
+	assertEncodedEntry(t, "error  lob law  n=123456789012345678901234567890\n", nil, zap.Reflect("n", n))
+	assertEncodedEntry(t, "error  lob law  ip=\"::1\"\n", opts, zap.Reflect("ip", net.IPv6loopback))
+	assertEncodedEntry(t, "error  lob law  o={}\n", opts, zap.Reflect("o", opaque{1}))
+}
+
+// upperStrings marshals v as JSON with all string values uppercased.
+func upperStrings(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return nil, err
+	}
+	var upper func(interface{}) interface{}
+	upper = func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case string:
+			return strings.ToUpper(v)
+		case map[string]interface{}:
+			for k, e := range v {
+				v[k] = upper(e)
+			}
+		case []interface{}:
+			for i, e := range v {
+				v[i] = upper(e)
+			}
+		}
+		return v
+	}
+	return json.Marshal(upper(generic))
+}
+
+func TestWithJSONMarshaler(t *testing.T) {
+	opts := []Option{WithJSONMarshaler(upperStrings)}
+	obj := map[string]interface{}{"name": "bob", "tags": []string{"a", "b"}}
+
+	assertEncodedEntry(t, "error  lob law  obj={\"name\":\"BOB\",\"tags\":[\"A\",\"B\"]}\n", opts, zap.Reflect("obj", obj))
+	assertEncodedEntry(t, "error  lob law  list=[\"X\"]\n", opts,
+		zap.Array("list", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+			return enc.AppendReflected("x")
+		})))
+	assertEncodedEntry(t, "error  lob law  obj={\n      \"name\": \"BOB\"\n    }\n",
+		append(opts, WithPrettyReflect(true)), zap.Reflect("obj", map[string]string{"name": "bob"}))
+
+	failing := errors.New("cannot marshal")
+	enc := NewTextEncoder(_optionsEncoderConfig, WithJSONMarshaler(func(interface{}) ([]byte, error) {
+		return nil, failing
+	}))
+	assert.Equal(t, failing, enc.AddReflected("k", 1))
+	assert.Equal(t, failing, enc.AddArray("k", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+		return enc.AppendReflected(1)
+	})))
+}
@@ -0,0 +1,76 @@
+package zaptextencoder
+
+import (
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// RingBuffer holds the last entries written to it, such as to dump them
+// when a service fails. Each write is taken as an encoded entry, line
+// ending and all, as zap writes one entry at a time. A RingBuffer is safe
+// for concurrent use.
+type RingBuffer struct {
+	mu      sync.RWMutex
+	entries []string
+	// next is the index in entries of the next entry written, and full
+	// is set once entries has wrapped around.
+	next int
+	full bool
+}
+
+// RingBufferSink returns a RingBuffer holding the last capacity entries,
+// and a zapcore.WriteSyncer writing to it. A capacity below 1 is taken as
+// 1.
+func RingBufferSink(capacity int) (zapcore.WriteSyncer, *RingBuffer) {
+	if capacity < 1 {
+		capacity = 1
+	}
+	rb := &RingBuffer{entries: make([]string, capacity)}
+	return rb, rb
+}
+
+// Write records p as an entry, overwriting the oldest one if rb is full.
+func (rb *RingBuffer) Write(p []byte) (int, error) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	rb.entries[rb.next] = string(p)
+	rb.next++
+	if rb.next == len(rb.entries) {
+		rb.next, rb.full = 0, true
+	}
+	return len(p), nil
+}
+
+// Sync does nothing, as rb is in memory.
+func (rb *RingBuffer) Sync() error {
+	return nil
+}
+
+// Entries returns the entries held by rb, oldest first.
+func (rb *RingBuffer) Entries() []string {
+	rb.mu.RLock()
+	defer rb.mu.RUnlock()
+	return rb.entriesLocked()
+}
+
+// Drain returns the entries held by rb like Entries, and removes them.
+func (rb *RingBuffer) Drain() []string {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	entries := rb.entriesLocked()
+	for i := range rb.entries {
+		rb.entries[i] = ""
+	}
+	rb.next, rb.full = 0, false
+	return entries
+}
+
+func (rb *RingBuffer) entriesLocked() []string {
+	if !rb.full {
+		return append([]string(nil), rb.entries[:rb.next]...)
+	}
+	entries := make([]string, 0, len(rb.entries))
+	entries = append(entries, rb.entries[rb.next:]...)
+	return append(entries, rb.entries[:rb.next]...)
+}
@@ -0,0 +1,40 @@
+package zaptextencoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRingBufferSink(t *testing.T) {
+	const capacity = 3
+	ws, rb := RingBufferSink(capacity)
+	logger := zap.New(zapcore.NewCore(NewTextEncoder(_optionsEncoderConfig), ws, zap.DebugLevel))
+	assert.Empty(t, rb.Entries(), "Expected no entries at first.")
+
+	logger.Info("lob law", zap.Int("i", 0))
+	assert.Equal(t, []string{"info   lob law  i=0\n"}, rb.Entries(), "Unexpected entries before wrapping around.")
+
+	for i := 1; i < capacity+5; i++ {
+		logger.Info("lob law", zap.Int("i", i))
+	}
+	expected := make([]string, 0, capacity)
+	for i := 5; i < capacity+5; i++ {
+		expected = append(expected, fmt.Sprintf("info   lob law  i=%d\n", i))
+	}
+	assert.Equal(t, expected, rb.Entries(), "Expected the last entries, oldest first.")
+	assert.NoError(t, ws.Sync(), "Unexpected error syncing.")
+
+	assert.Equal(t, expected, rb.Drain(), "Expected Drain to return the entries.")
+	assert.Empty(t, rb.Entries(), "Expected Drain to remove the entries.")
+	logger.Info("lob law", zap.Int("i", 8))
+	assert.Equal(t, []string{"info   lob law  i=8\n"}, rb.Entries(), "Unexpected entries after draining.")
+
+	_, rb = RingBufferSink(0)
+	rb.Write([]byte("a\n"))
+	rb.Write([]byte("b\n"))
+	assert.Equal(t, []string{"b\n"}, rb.Entries(), "Expected a capacity of at least 1.")
+}
@@ -0,0 +1,52 @@
+package zaptextencoder
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// KeySanitizer rewrites the keys of fields before they are written.
+type KeySanitizer interface {
+	Sanitize(key string) string
+}
+
+// WithKeySanitizer sets the KeySanitizer applied to every key, including
+// its namespaces. Encoders from NewLogfmtEncoder still replace whatever
+// logfmt does not allow afterwards.
+func WithKeySanitizer(s KeySanitizer) Option {
+	return func(enc *textEncoder) {
+		enc.keySanitizer = s
+	}
+}
+
+// LogfmtKeySanitizer replaces the characters logfmt does not allow in keys,
+// spaces, '=', '"' and control characters, with '_', such as "my key" with
+// "my_key". Empty keys become "_".
+type LogfmtKeySanitizer struct{}
+
+// Sanitize implements KeySanitizer.
+func (LogfmtKeySanitizer) Sanitize(key string) string {
+	return logfmtKey(key)
+}
+
+// StrictKeySanitizer prefixes keys holding characters logfmt does not allow
+// with "invalid_key.", and escapes them as in a URL query, such as "my key"
+// with "invalid_key.my+key", so that such fields are easy to find and fix
+// while telling apart the fields of different keys.
+type StrictKeySanitizer struct{}
+
+// Sanitize implements KeySanitizer.
+func (s StrictKeySanitizer) Sanitize(key string) string {
+	if s.Check(key) != nil {
+		return "invalid_key." + url.QueryEscape(key)
+	}
+	return key
+}
+
+// Check returns an error if key holds characters logfmt does not allow.
+func (StrictKeySanitizer) Check(key string) error {
+	if logfmtKey(key) != key {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	return nil
+}
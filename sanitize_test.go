@@ -0,0 +1,37 @@
+package zaptextencoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestWithKeySanitizer(t *testing.T) {
+	assertEncodedEntry(t, "error  lob law  my_key=1  a_b=2  ok=3\n",
+		[]Option{WithKeySanitizer(LogfmtKeySanitizer{})},
+		zap.Int("my key", 1), zap.Int("a=b", 2), zap.Int("ok", 3))
+	assertEncodedEntry(t, "error  lob law  invalid_key.my+key=1  invalid_key.a%3Db=2  ok=3\n",
+		[]Option{WithKeySanitizer(StrictKeySanitizer{})},
+		zap.Int("my key", 1), zap.Int("a=b", 2), zap.Int("ok", 3))
+	assertEncodedEntry(t, "error  lob law  my key=1\n", nil, zap.Int("my key", 1))
+	assertEncodedEntry(t, "error  lob law  a_b.c_d=1\n",
+		[]Option{WithKeySanitizer(LogfmtKeySanitizer{})},
+		zap.Namespace("a b"), zap.Int("c d", 1))
+}
+
+func TestStrictKeySanitizerCheck(t *testing.T) {
+	assert.NoError(t, StrictKeySanitizer{}.Check("ok"))
+	assert.EqualError(t, StrictKeySanitizer{}.Check("my key"), `invalid key "my key"`)
+	assert.Error(t, StrictKeySanitizer{}.Check(`a"b`))
+}
+
+func TestStrictKeySanitizerDistinct(t *testing.T) {
+	s := StrictKeySanitizer{}
+	assert.Equal(t, "ok", s.Sanitize("ok"))
+	assert.NotEqual(t, s.Sanitize("a b"), s.Sanitize("a=b"))
+	assert.NotEqual(t, s.Sanitize("a b"), s.Sanitize("a\tb"))
+	for _, key := range []string{"a b", "a=b", `a"b`, "a\nb", ""} {
+		assert.NoError(t, s.Check(s.Sanitize(key)), "Expected %q to sanitize to a valid key.", key)
+	}
+}
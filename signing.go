@@ -0,0 +1,80 @@
+package zaptextencoder
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const _hmacField = " hmac="
+
+// ErrNoHMAC is returned by VerifyLogLine for lines without an hmac field.
+var ErrNoHMAC = errors.New("no hmac field")
+
+// signingWriter appends the HMAC of every entry to it, see
+// SigningWriteSyncer.
+type signingWriter struct {
+	zapcore.WriteSyncer
+	key []byte
+}
+
+// SigningWriteSyncer returns a zapcore.WriteSyncer writing entries to ws
+// with an hmac field appended to each, before its line ending, holding the
+// hex-encoded HMAC-SHA256 of the entry with key, so that tampering with
+// the entries can be detected by VerifyLogLine.
+func SigningWriteSyncer(ws zapcore.WriteSyncer, key []byte) zapcore.WriteSyncer {
+	return &signingWriter{WriteSyncer: ws, key: append([]byte(nil), key...)}
+}
+
+// Write signs p, which zap writes one entry at a time.
+func (w *signingWriter) Write(p []byte) (int, error) {
+	content, ending := splitLineEnding(p)
+	mac := hmac.New(sha256.New, w.key)
+	mac.Write(content)
+
+	signed := make([]byte, 0, len(p)+len(_hmacField)+2*sha256.Size)
+	signed = append(signed, content...)
+	signed = append(signed, _hmacField...)
+	signed = append(signed, hex.EncodeToString(mac.Sum(nil))...)
+	signed = append(signed, ending...)
+	if _, err := w.WriteSyncer.Write(signed); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// splitLineEnding splits the trailing "\n" or "\r\n" off p.
+func splitLineEnding(p []byte) (content, ending []byte) {
+	if bytes.HasSuffix(p, []byte("\r\n")) {
+		return p[:len(p)-2], p[len(p)-2:]
+	}
+	if bytes.HasSuffix(p, []byte("\n")) {
+		return p[:len(p)-1], p[len(p)-1:]
+	}
+	return p, nil
+}
+
+// VerifyLogLine reports whether the hmac field at the end of a line written
+// by SigningWriteSyncer matches the rest of the line, with key. Entries
+// spanning several lines, such as those with a stacktrace, must be given
+// whole. It returns ErrNoHMAC if the line has no hmac field, and an error
+// if the field isn't valid hex.
+func VerifyLogLine(line string, key []byte) (bool, error) {
+	content, _ := splitLineEnding([]byte(line))
+	i := strings.LastIndex(string(content), _hmacField)
+	if i < 0 {
+		return false, ErrNoHMAC
+	}
+	sum, err := hex.DecodeString(string(content[i+len(_hmacField):]))
+	if err != nil {
+		return false, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(content[:i])
+	return hmac.Equal(sum, mac.Sum(nil)), nil
+}
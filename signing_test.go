@@ -0,0 +1,59 @@
+package zaptextencoder
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSigningWriteSyncer(t *testing.T) {
+	key := []byte("secret")
+	var out bytes.Buffer
+	logger := zap.New(zapcore.NewCore(NewTextEncoder(_optionsEncoderConfig), SigningWriteSyncer(zapcore.AddSync(&out), key), zap.DebugLevel))
+	for i := 0; i < 10; i++ {
+		logger.Info("lob law", zap.Int("i", i))
+	}
+
+	lines := strings.SplitAfter(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 10, "Expected a line per entry.")
+	for _, line := range lines {
+		assert.Regexp(t, `^info   lob law  i=\d hmac=[0-9a-f]{64}\n?$`, line, "Unexpected signed line.")
+	}
+	lines[3] = strings.Replace(lines[3], "i=3", "i=9", 1)
+
+	for i, line := range lines {
+		ok, err := VerifyLogLine(line, key)
+		require.NoError(t, err, "Unexpected error verifying %q.", line)
+		assert.Equal(t, i != 3, ok, "Unexpected verification of %q.", line)
+	}
+
+	ok, err := VerifyLogLine(lines[0], []byte("other"))
+	assert.NoError(t, err, "Unexpected error verifying with another key.")
+	assert.False(t, ok, "Expected another key to fail verification.")
+}
+
+func TestSigningWriteSyncerLineEndings(t *testing.T) {
+	key := []byte("secret")
+	for _, entry := range []string{"a=1\r\n", "a=1", "a=1\nstack\n"} {
+		var out bytes.Buffer
+		ws := SigningWriteSyncer(zapcore.AddSync(&out), key)
+		n, err := ws.Write([]byte(entry))
+		require.NoError(t, err, "Unexpected error writing %q.", entry)
+		assert.Equal(t, len(entry), n, "Expected the length of the entry.")
+		ok, err := VerifyLogLine(out.String(), key)
+		assert.NoError(t, err, "Unexpected error verifying %q.", out.String())
+		assert.True(t, ok, "Expected %q to verify.", out.String())
+	}
+}
+
+func TestVerifyLogLineErrors(t *testing.T) {
+	_, err := VerifyLogLine("info  lob law\n", nil)
+	assert.Equal(t, ErrNoHMAC, err, "Expected an error without an hmac field.")
+	_, err = VerifyLogLine("info  lob law hmac=xyz\n", nil)
+	assert.Error(t, err, "Expected an error for an invalid hmac field.")
+}
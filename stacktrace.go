@@ -0,0 +1,84 @@
+package zaptextencoder
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// WithMaxStacktraceDepth keeps only the first n frames of entry stack
+// traces, replacing the rest with a "... (N frames omitted)" line. Zero, the
+// default, keeps whole stack traces.
+func WithMaxStacktraceDepth(n int) Option {
+	return func(enc *textEncoder) {
+		enc.maxStacktraceDepth = n
+	}
+}
+
+// WithTrimStacktracePaths removes the GOROOT and first GOPATH directories
+// from the file paths of entry stack traces, so that
+// "/usr/local/go/src/runtime/proc.go" becomes "runtime/proc.go".
+func WithTrimStacktracePaths(enabled bool) Option {
+	return func(enc *textEncoder) {
+		enc.stacktraceTrimmer = nil
+		if enabled {
+			enc.stacktraceTrimmer = newStacktraceTrimmer(runtime.GOROOT(), os.Getenv("GOPATH"))
+		}
+	}
+}
+
+// newStacktraceTrimmer returns a replacer removing the goroot and first
+// gopath directories from paths. Module and source directories go first so
+// that the longest prefix wins.
+func newStacktraceTrimmer(goroot, gopath string) *strings.Replacer {
+	var oldnew []string
+	if list := filepath.SplitList(gopath); len(list) > 0 && list[0] != "" {
+		dir := filepath.ToSlash(list[0])
+		oldnew = append(oldnew, dir+"/pkg/mod/", "", dir+"/src/", "", dir+"/", "")
+	}
+	if goroot != "" {
+		dir := filepath.ToSlash(goroot)
+		oldnew = append(oldnew, dir+"/src/", "", dir+"/", "")
+	}
+	return strings.NewReplacer(oldnew...)
+}
+
+// formatStacktrace applies the stack trace options of enc to stack.
+func (enc *textEncoder) formatStacktrace(stack string) string {
+	if enc.maxStacktraceDepth > 0 {
+		stack = truncateStacktrace(stack, enc.maxStacktraceDepth)
+	}
+	if enc.stacktraceTrimmer != nil {
+		stack = enc.stacktraceTrimmer.Replace(stack)
+	}
+	return stack
+}
+
+// truncateStacktrace keeps the first n frames of stack. A frame is a
+// function line followed by its tab-indented location lines, as written by
+// both zap and runtime.Stack; "goroutine" headers aren't frames.
+func truncateStacktrace(stack string, n int) string {
+	frames, cut := 0, -1
+	for i := 0; i < len(stack); {
+		end := strings.IndexByte(stack[i:], '\n')
+		if end < 0 {
+			end = len(stack)
+		} else {
+			end += i
+		}
+		line := stack[i:end]
+		if line != "" && line[0] != '\t' && !strings.HasPrefix(line, "goroutine ") {
+			if frames == n {
+				cut = i
+			}
+			frames++
+		}
+		i = end + 1
+	}
+	if cut < 0 {
+		return stack
+	}
+	return stack[:cut] + "... (" + strconv.Itoa(frames-n) + " frames omitted)"
+}
@@ -0,0 +1,116 @@
+package zaptextencoder
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestTruncateStacktrace(t *testing.T) {
+	stack := "a.one()\n\t/a/one.go:1\n" +
+		"a.two()\n\t/a/two.go:2\n" +
+		"a.three()\n\t/a/three.go:3"
+
+	tests := []struct {
+		desc     string
+		n        int
+		expected string
+	}{
+		{"one frame", 1, "a.one()\n\t/a/one.go:1\n... (2 frames omitted)"},
+		{"two frames", 2, "a.one()\n\t/a/one.go:1\na.two()\n\t/a/two.go:2\n... (1 frames omitted)"},
+		{"all frames", 3, stack},
+		{"more than all frames", 10, stack},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.expected, truncateStacktrace(stack, tt.n), "Unexpected truncated stack trace.")
+		})
+	}
+}
+
+func recurse(depth int) string {
+	if depth == 0 {
+		buf := make([]byte, 1<<16)
+		return string(buf[:runtime.Stack(buf, false)])
+	}
+	return recurse(depth - 1)
+}
+
+func TestWithMaxStacktraceDepth(t *testing.T) {
+	stack := recurse(10)
+	cfg := zapcore.EncoderConfig{MessageKey: "M", StacktraceKey: "S"}
+	ent := zapcore.Entry{Level: zapcore.ErrorLevel, Message: "lob law", Stack: stack}
+
+	buf, err := NewTextEncoder(cfg, WithMaxStacktraceDepth(3)).EncodeEntry(ent, nil)
+	if !assert.NoError(t, err, "Unexpected text encoding error.") {
+		return
+	}
+	defer buf.Free()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if assert.Len(t, lines, 9, "Expected the message, a goroutine header, 3 frames and the note.") {
+		assert.Equal(t, "lob law", lines[0], "Unexpected message line.")
+		assert.True(t, strings.HasPrefix(lines[1], "goroutine "), "Expected the goroutine header to be kept.")
+		for i := 0; i < 3; i++ {
+			assert.False(t, strings.HasPrefix(lines[2+2*i], "\t"), "Expected a function line.")
+			assert.True(t, strings.HasPrefix(lines[3+2*i], "\t"), "Expected a location line.")
+		}
+		assert.Contains(t, lines[2], "recurse", "Expected the innermost frame first.")
+		assert.Regexp(t, `^\.\.\. \(\d+ frames omitted\)$`, lines[8], "Unexpected truncation note.")
+	}
+
+	buf2, err := NewTextEncoder(cfg).EncodeEntry(ent, nil)
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		assert.Equal(t, "lob law\n"+stack+"\n", buf2.String(), "Expected the whole stack trace by default.")
+	}
+	buf2.Free()
+}
+
+func TestNewStacktraceTrimmer(t *testing.T) {
+	stack := "main.main()\n" +
+		"\t/home/user/app/main.go:12\n" +
+		"github.com/x/y.Z()\n" +
+		"\t/home/user/go/pkg/mod/github.com/x/y@v1.0.0/z.go:34\n" +
+		"old/style.Pkg()\n" +
+		"\t/home/user/go/src/old/style/pkg.go:56\n" +
+		"runtime.main()\n" +
+		"\t/usr/local/go/src/runtime/proc.go:250"
+
+	expected := "main.main()\n" +
+		"\t/home/user/app/main.go:12\n" +
+		"github.com/x/y.Z()\n" +
+		"\tgithub.com/x/y@v1.0.0/z.go:34\n" +
+		"old/style.Pkg()\n" +
+		"\told/style/pkg.go:56\n" +
+		"runtime.main()\n" +
+		"\truntime/proc.go:250"
+
+	gopath := strings.Join([]string{"/home/user/go", "/other/go"}, string(os.PathListSeparator))
+	assert.Equal(t, expected, newStacktraceTrimmer("/usr/local/go", gopath).Replace(stack), "Unexpected trimmed stack trace.")
+	assert.Equal(t, stack, newStacktraceTrimmer("", "").Replace(stack), "Expected no trimming without GOROOT or GOPATH.")
+}
+
+func TestWithTrimStacktracePaths(t *testing.T) {
+	goroot := filepath.ToSlash(runtime.GOROOT())
+	stack := "runtime.main()\n\t" + goroot + "/src/runtime/proc.go:250"
+	cfg := zapcore.EncoderConfig{MessageKey: "M", StacktraceKey: "S"}
+	ent := zapcore.Entry{Level: zapcore.ErrorLevel, Message: "lob law", Stack: stack}
+
+	buf, err := NewTextEncoder(cfg, WithTrimStacktracePaths(true)).EncodeEntry(ent, nil)
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		assert.Equal(t, "lob law\nruntime.main()\n\truntime/proc.go:250\n", buf.String(), "Expected GOROOT to be trimmed.")
+	}
+	buf.Free()
+
+	buf, err = NewTextEncoder(cfg, WithTrimStacktracePaths(false)).EncodeEntry(ent, nil)
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		assert.Equal(t, "lob law\n"+stack+"\n", buf.String(), "Expected no trimming when disabled.")
+	}
+	buf.Free()
+}
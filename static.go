@@ -0,0 +1,65 @@
+package zaptextencoder
+
+import (
+	"os"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// hostname is os.Hostname, replaced by tests.
+var hostname = os.Hostname
+
+// WithAutoHostname adds a hostname field, looked up once when the encoder is
+// created, to every entry. The host's name is "unknown" if it can't be
+// looked up.
+func WithAutoHostname() Option {
+	return func(enc *textEncoder) {
+		name, err := hostname()
+		if err != nil || name == "" {
+			name = "unknown"
+		}
+		enc.static = append(enc.static, zap.String("hostname", name))
+	}
+}
+
+// readBuildInfo is debug.ReadBuildInfo, replaced by tests.
+var readBuildInfo = debug.ReadBuildInfo
+
+// WithAutoBuildInfo adds the build information of the binary, read once when
+// the encoder is created, to every entry: the go_version it was built with,
+// its main module and module_version, and its vcs_revision. Fields whose
+// value is unknown are left out, and all of them are if the binary wasn't
+// built with module support.
+func WithAutoBuildInfo() Option {
+	return func(enc *textEncoder) {
+		info, ok := readBuildInfo()
+		if !ok {
+			return
+		}
+		for _, f := range []struct{ key, val string }{
+			{"go_version", goVersion(info)},
+			{"module", info.Main.Path},
+			{"module_version", info.Main.Version},
+			{"vcs_revision", vcsRevision(info)},
+		} {
+			if f.val != "" {
+				enc.static = append(enc.static, zap.String(f.key, f.val))
+			}
+		}
+	}
+}
+
+// addStatic encodes fields into the encoder's shared fields, so that they
+// head every entry and its clones without being encoded again.
+func (enc *textEncoder) addStatic(fields []zapcore.Field) {
+	for i := range fields {
+		fields[i].AddTo(enc)
+	}
+	if n := enc.buf.Len(); n > 0 {
+		enc.shared = append(enc.shared[:len(enc.shared):len(enc.shared)], enc.buf.Bytes()[:n:n])
+		enc.buf = bufferPool.getKept()
+		enc.fieldStart, enc.elemStart = 0, 0
+	}
+}
@@ -0,0 +1,55 @@
+package zaptextencoder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func withHostname(t *testing.T, name string, err error) {
+	orig := hostname
+	hostname = func() (string, error) { return name, err }
+	t.Cleanup(func() { hostname = orig })
+}
+
+func TestWithAutoHostname(t *testing.T) {
+	tests := []struct {
+		desc     string
+		name     string
+		err      error
+		expected string
+	}{
+		{"name", "db-1", nil, "error  hostname=\"db-1\"  lob law  k=1\n"},
+		{"error", "", errors.New("no name"), "error  hostname=\"unknown\"  lob law  k=1\n"},
+		{"empty", "", nil, "error  hostname=\"unknown\"  lob law  k=1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			withHostname(t, tt.name, tt.err)
+			assertEncodedEntry(t, tt.expected, []Option{WithAutoHostname()}, zap.Int("k", 1))
+		})
+	}
+
+	t.Run("looked up once", func(t *testing.T) {
+		calls := 0
+		orig := hostname
+		hostname = func() (string, error) { calls++; return "db-1", nil }
+		defer func() { hostname = orig }()
+
+		enc := NewTextEncoder(_optionsEncoderConfig, WithAutoHostname())
+		clone := enc.Clone()
+		clone.AddInt("k", 1)
+		for i := 0; i < 3; i++ {
+			buf, err := clone.EncodeEntry(_optionsEntry, []zapcore.Field{zap.Int("n", i)})
+			if assert.NoError(t, err, "Unexpected text encoding error.") {
+				assert.Contains(t, buf.String(), "error  hostname=\"db-1\"  k=1  lob law", "Expected the hostname first.")
+			}
+			buf.Free()
+		}
+		assert.Equal(t, 1, calls, "Expected the hostname to be looked up once.")
+	})
+}
@@ -1,16 +1,22 @@
 package zaptextencoder
 
 import (
+	"bytes"
 	"encoding/base64"
-	"encoding/json"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"math"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+	"unicode"
 	"unicode/utf8"
 
 	"go.uber.org/zap/buffer"
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/text/unicode/norm"
 )
 
 const _hex = "0123456789abcdef"
@@ -30,8 +36,6 @@ func putSliceEncoder(e *sliceArrayEncoder) {
 	_sliceEncoderPool.Put(e)
 }
 
-var bufferPool = buffer.NewPool()
-
 var _textPool = sync.Pool{
 	New: func() interface{} {
 		return &textEncoder{}
@@ -45,137 +49,435 @@ func getTextEncoder() *textEncoder {
 func putTextEncoder(enc *textEncoder) {
 	enc.EncoderConfig = nil
 	enc.buf = nil
+	enc.shared = nil
+	enc.spans = nil
+	enc.namespaces = nil
+	enc.conditional = nil
 	_textPool.Put(enc)
 }
 
+// TextEncoder is implemented by the encoders returned by NewTextEncoder and
+// NewLogfmtEncoder, which can be type-asserted to it for convenience methods
+// that spare callers from freeing buffers.
+type TextEncoder interface {
+	zapcore.Encoder
+
+	// EncodeEntryString encodes an entry and returns it as a string.
+	EncodeEntryString(ent zapcore.Entry, fields []zapcore.Field) (string, error)
+	// EncodeEntryTo encodes an entry and writes it to w.
+	EncodeEntryTo(w io.Writer, ent zapcore.Entry, fields []zapcore.Field) error
+}
+
+var _ TextEncoder = (*textEncoder)(nil)
+
 type textEncoder struct {
 	*zapcore.EncoderConfig
 
-	buf       *buffer.Buffer
+	buf *buffer.Buffer
+	// pool hands out the buffers of entries, and of the scratch encoders
+	// used to write them, in place of bufferPool if set, see buffers.
+	pool *textBufferPool
+	// shared holds the fields of the encoders this one was cloned from,
+	// which are written before buf, see context. It aliases their buffers
+	// rather than copying them, which is safe as those are only ever
+	// appended to past the aliased bytes.
+	shared    [][]byte
 	separator string
+	keyDelim  string
+	quoteMode QuoteMode
+	quote     byte
+
+	escapeStyle EscapeStyle
+	asciiOnly   bool
+	// normForm is only applied if normalize is set, as the zero norm.Form
+	// is NFC.
+	normalize bool
+	normForm  norm.Form
+	// invalidReplacement is only written if replaceInvalid is set, as it
+	// may be empty.
+	replaceInvalid     bool
+	invalidReplacement string
+
+	arraySep   string
+	arrayBegin string
+	arrayEnd   string
+
+	linePrefix string
+	// fieldStart is the offset in buf of the first field of the line or
+	// object being written, which is not preceded by a separator.
+	fieldStart int
+	// elemStart is the offset in buf of the first element of the array
+	// being written, or of the value following a key. Elements are only
+	// separated from what comes after it.
+	elemStart    int
+	noLineEnding bool
+	logfmt       bool
+	keySanitizer KeySanitizer
+	keyCache     *KeyCache
+
+	fieldHooks       []FieldHook
+	contextExtractor ContextExtractor
+
+	binaryEncoding BinaryEncoding
+	intEncoding    IntEncoding
+	boolEncoding   BoolEncoding
+
+	errorTypes         bool
+	errorCauseDepth    int
+	unwrapErrors       bool
+	maxErrorChainDepth int
+
+	maxStacktraceDepth int
+	stacktraceTrimmer  *strings.Replacer
+
+	// floatPrecision is only used if fixedFloatPrecision is set, so that
+	// the zero value keeps the shortest representation.
+	floatPrecision      int
+	fixedFloatPrecision bool
+	floatFormat         FloatFormat
+
+	maxValueLen   int
+	maxValueBytes int
+	truncSuffix   string
+
+	messageColors ColorScheme
+	timeColor     string
+	keyColors     map[string]string
+
+	dedup      DeduplicateStrategy
+	maxFields  int
+	sortFields bool
+	fieldOrder map[string]int
+
+	sensitiveKeys sensitiveKeys
+	mask          string
+	redactions    []RedactionRule
+	// depth is the nesting level of the array or object being written, and
+	// spans the top-level fields written so far, see recordField.
+	depth int
+	spans []fieldSpan
+
+	namespaces     []string
+	namespaceSep   string
+	flattenObjects bool
+	objectEncoding ObjectEncoding
+	objectBegin    string
+	objectEnd      string
+
+	prettyReflect bool
+	reflectIndent string
+	typeEncoders  TypeEncoderRegistry
+	decomposeURLs bool
+
+	preferStringer      bool
+	preferTextMarshaler bool
+	jsonMarshaler       func(v interface{}) ([]byte, error)
+
+	// static holds the fields added by WithAutoHostname and the like until
+	// they're encoded, when the encoder is created.
+	static []zapcore.Field
+	// conditional holds the ConditionalFields given to logger.With, which
+	// are only encoded with the entries at their level.
+	conditional []conditionalValue
+
+	levelCounters []LevelCounter
 }
 
-// NewTextEncoder creates a key=value encoder
-func NewTextEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
-	return &textEncoder{
+// NewTextEncoder creates a key=value encoder. All colors are disabled if
+// the environment asks for it when the encoder is created, see ColorEnabled.
+func NewTextEncoder(cfg zapcore.EncoderConfig, opts ...Option) zapcore.Encoder {
+	enc := &textEncoder{
 		EncoderConfig: &cfg,
-		buf:           bufferPool.Get(),
+		buf:           bufferPool.getKept(),
 		separator:     "  ",
+		truncSuffix:   "…",
+		mask:          "[REDACTED]",
+
+		maxErrorChainDepth: 10,
+	}
+	for _, opt := range opts {
+		opt(enc)
+	}
+	if !ColorEnabled(nil) {
+		enc.disableColors()
 	}
+	enc.addStatic(enc.static)
+	enc.static = nil
+	return enc
+}
+
+// NewTextEncoderWithFields creates a key=value encoder like NewTextEncoder
+// with fields encoded once up front. They're written first in every entry
+// and shared read-only by clones, in the same way as the fields of a parent
+// encoder.
+func NewTextEncoderWithFields(cfg zapcore.EncoderConfig, fields ...zapcore.Field) zapcore.Encoder {
+	enc := NewTextEncoder(cfg).(*textEncoder)
+	enc.addStatic(fields)
+	return enc
 }
 
 func (enc *textEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
-	enc.addKey(key)
-	return enc.AppendArray(arr)
+	if enc.logfmt && enc.depth == 0 {
+		return enc.addLogfmtValue(key, func(scratch *textEncoder) error {
+			return scratch.AppendArray(arr)
+		})
+	}
+	if !enc.addKey(key) {
+		return nil
+	}
+	err := enc.AppendArray(arr)
+	enc.closeKey(key)
+	return err
 }
 
 func (enc *textEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
-	enc.addKey(key)
-	return enc.AppendObject(obj)
+	if v, ok := obj.(urlValue); ok && !enc.sensitiveKeys.match(key) {
+		return enc.addURL(key, v)
+	}
+	if v, ok := obj.(conditionalValue); ok {
+		return enc.addConditional(v)
+	}
+	if enc.flattenObjects && !enc.sensitiveKeys.match(key) {
+		return enc.addFlatObject(key, obj)
+	}
+	if enc.logfmt && enc.depth == 0 {
+		return enc.addLogfmtValue(key, func(scratch *textEncoder) error {
+			return scratch.AppendObject(obj)
+		})
+	}
+	if !enc.addKey(key) {
+		return nil
+	}
+	err := enc.AppendObject(obj)
+	enc.closeKey(key)
+	return err
 }
 
 func (enc *textEncoder) AddBinary(key string, val []byte) {
-	enc.AddString(key, base64.StdEncoding.EncodeToString(val))
+	if !enc.addKey(key) {
+		return
+	}
+	enc.AppendBinary(val)
+	enc.closeKey(key)
 }
 
 func (enc *textEncoder) AddByteString(key string, val []byte) {
-	enc.addKey(key)
+	if !enc.addKey(key) {
+		return
+	}
 	enc.AppendByteString(val)
+	enc.closeKey(key)
 }
 
 func (enc *textEncoder) AddBool(key string, val bool) {
-	enc.addKey(key)
+	if !enc.addKey(key) {
+		return
+	}
 	enc.AppendBool(val)
+	enc.closeKey(key)
 }
 
 func (enc *textEncoder) AddComplex128(key string, val complex128) {
-	enc.addKey(key)
+	if !enc.addKey(key) {
+		return
+	}
 	enc.AppendComplex128(val)
+	enc.closeKey(key)
 }
 
 func (enc *textEncoder) AddDuration(key string, val time.Duration) {
-	enc.addKey(key)
+	if !enc.addKey(key) {
+		return
+	}
 	enc.AppendDuration(val)
+	enc.closeKey(key)
 }
 
 func (enc *textEncoder) AddFloat64(key string, val float64) {
-	enc.addKey(key)
+	if !enc.addKey(key) {
+		return
+	}
 	enc.AppendFloat64(val)
+	enc.closeKey(key)
 }
 
 func (enc *textEncoder) AddInt64(key string, val int64) {
-	enc.addKey(key)
+	if !enc.addKey(key) {
+		return
+	}
 	enc.AppendInt64(val)
+	enc.closeKey(key)
 }
 
+// AddReflected writes obj as JSON, unless a TypeEncoder is registered for
+// it or it is written as a string, see WithPreferStringerInReflect and
+// WithPreferTextMarshalerInReflect. With the default marshaler, map keys
+// are sorted as by encoding/json, so the output of maps is deterministic; a
+// marshaler set with WithJSONMarshaler may not give that guarantee.
 func (enc *textEncoder) AddReflected(key string, obj interface{}) error {
-	marshaled, err := json.Marshal(obj)
+	if fn := enc.typeEncoders.lookup(obj); fn != nil {
+		if !enc.addKey(key) {
+			return nil
+		}
+		err := fn(obj, enc)
+		enc.closeKey(key)
+		return err
+	}
+	if text, ok, err := enc.reflectedText(obj); err != nil {
+		return err
+	} else if ok {
+		enc.AddString(key, text)
+		return nil
+	}
+	if enc.prettyReflect {
+		return enc.addPrettyReflected(key, obj)
+	}
+	marshaled, err := enc.marshalJSON(obj)
 	if err != nil {
 		return err
 	}
-	enc.addKey(key)
+	if !enc.addKey(key) {
+		return nil
+	}
+	if enc.logfmt && enc.depth == 0 {
+		enc.AppendByteString(marshaled)
+		enc.closeKey(key)
+		return nil
+	}
 	_, err = enc.buf.Write(marshaled)
+	enc.closeKey(key)
 	return err
 }
 
-func (enc *textEncoder) OpenNamespace(key string) {
-}
-
 func (enc *textEncoder) AddString(key, val string) {
-	enc.addKey(key)
+	if !enc.addKey(key) {
+		return
+	}
 	enc.AppendString(val)
+	enc.closeKey(key)
 }
 
 func (enc *textEncoder) AddTime(key string, val time.Time) {
-	enc.addKey(key)
+	if !enc.addKey(key) {
+		return
+	}
 	enc.AppendTime(val)
+	enc.closeKey(key)
 }
 
 func (enc *textEncoder) AddUint64(key string, val uint64) {
-	enc.addKey(key)
+	if !enc.addKey(key) {
+		return
+	}
 	enc.AppendUint64(val)
+	enc.closeKey(key)
 }
 
 func (enc *textEncoder) AppendArray(arr zapcore.ArrayMarshaler) error {
 	enc.addElementSeparator()
-	enc.buf.AppendByte('[')
+	begin, end := enc.arrayDelimiters()
+	enc.buf.AppendString(begin)
+	leave := enc.enterNested()
+	elemStart := enc.elemStart
+	enc.elemStart = enc.buf.Len()
 	err := arr.MarshalLogArray(enc)
-	enc.buf.AppendByte(']')
+	enc.elemStart = elemStart
+	leave()
+	enc.buf.AppendString(end)
 	return err
 }
 
 func (enc *textEncoder) AppendObject(obj zapcore.ObjectMarshaler) error {
 	enc.addElementSeparator()
-	enc.buf.AppendByte('{')
+	if enc.objectEncoding == ObjectEncodingJSON {
+		return enc.appendJSONObject(obj)
+	}
+	begin, end := enc.objectDelimiters()
+	enc.buf.AppendString(begin)
+	leave := enc.enterNested()
+	fieldStart, elemStart := enc.fieldStart, enc.elemStart
+	enc.fieldStart = enc.buf.Len()
 	err := obj.MarshalLogObject(enc)
-	enc.buf.AppendByte('}')
+	enc.fieldStart, enc.elemStart = fieldStart, elemStart
+	leave()
+	enc.buf.AppendString(end)
 	return err
 }
 
+// AppendBinary appends val as a string in the configured BinaryEncoding.
+func (enc *textEncoder) AppendBinary(val []byte) {
+	var s string
+	switch enc.binaryEncoding {
+	case BinaryBase64URL:
+		s = base64.URLEncoding.EncodeToString(val)
+	case BinaryHex:
+		s = hex.EncodeToString(val)
+	case BinaryHexUpper:
+		s = strings.ToUpper(hex.EncodeToString(val))
+	default:
+		s = base64.StdEncoding.EncodeToString(val)
+	}
+	enc.AppendString(s)
+}
+
+// AppendBool appends val in the configured BoolEncoding.
 func (enc *textEncoder) AppendBool(val bool) {
 	enc.addElementSeparator()
-	enc.buf.AppendBool(val)
+	switch enc.boolEncoding {
+	case BoolYesNo:
+		enc.buf.AppendString(boolWord(val, "yes", "no"))
+	case BoolOnOff:
+		enc.buf.AppendString(boolWord(val, "on", "off"))
+	case BoolOneZero:
+		enc.buf.AppendString(boolWord(val, "1", "0"))
+	default:
+		enc.buf.AppendBool(val)
+	}
+}
+
+func boolWord(val bool, t, f string) string {
+	if val {
+		return t
+	}
+	return f
 }
 
 func (enc *textEncoder) AppendByteString(val []byte) {
 	enc.addElementSeparator()
-	enc.buf.AppendByte('"')
+	if enc.normalize {
+		val = enc.normForm.Bytes(val)
+	}
+	suffix := ""
+	if i := enc.truncateByteStringIndex(val); i >= 0 {
+		val, suffix = val[:i], enc.truncSuffix
+	}
+	if len(enc.redactions) > 0 {
+		val = enc.redactByteString(val)
+	}
+	quote := enc.quoteMode == QuoteModeAlways || enc.byteStringNeedsQuotes(val) ||
+		(suffix != "" && enc.stringNeedsQuotes(suffix))
+	if quote {
+		enc.buf.AppendByte(enc.quoteChar())
+	}
 	enc.safeAddByteString(val)
-	enc.buf.AppendByte('"')
+	enc.safeAddString(suffix)
+	if quote {
+		enc.buf.AppendByte(enc.quoteChar())
+	}
 }
 
 func (enc *textEncoder) AppendComplex128(val complex128) {
 	enc.addElementSeparator()
 	// Cast to a platform-independent, fixed-size type.
 	r, i := float64(real(val)), float64(imag(val))
-	enc.buf.AppendByte('"')
+	enc.buf.AppendByte(enc.quoteChar())
 	// Because we're always in a quoted string, we can use strconv without
 	// special-casing NaN and +/-Inf.
 	enc.buf.AppendFloat(r, 64)
 	enc.buf.AppendByte('+')
 	enc.buf.AppendFloat(i, 64)
 	enc.buf.AppendByte('i')
-	enc.buf.AppendByte('"')
+	enc.buf.AppendByte(enc.quoteChar())
 }
 
 func (enc *textEncoder) AppendDuration(val time.Duration) {
@@ -186,17 +488,42 @@ func (enc *textEncoder) AppendDuration(val time.Duration) {
 	if cur == enc.buf.Len() {
 		// User-supplied EncodeDuration is a no-op. Fall back to nanoseconds to keep
 		// JSON valid.
-		enc.AppendInt64(int64(val))
+		enc.appendDecimal(int64(val))
 	}
 }
 
 func (enc *textEncoder) AppendInt64(val int64) {
+	enc.addElementSeparator()
+	if enc.intEncoding == IntDecimal {
+		enc.buf.AppendInt(val)
+		return
+	}
+	u := uint64(val)
+	if val < 0 {
+		enc.buf.AppendByte('-')
+		u = -u
+	}
+	enc.appendUintDigits(u)
+}
+
+// appendDecimal appends val in decimal whatever the IntEncoding, which is
+// meant for the integers of callers, not those the encoder writes itself.
+func (enc *textEncoder) appendDecimal(val int64) {
 	enc.addElementSeparator()
 	enc.buf.AppendInt(val)
 }
 
 func (enc *textEncoder) AppendReflected(val interface{}) error {
-	marshaled, err := json.Marshal(val)
+	if fn := enc.typeEncoders.lookup(val); fn != nil {
+		return fn(val, enc)
+	}
+	if text, ok, err := enc.reflectedText(val); err != nil {
+		return err
+	} else if ok {
+		enc.AppendString(text)
+		return nil
+	}
+	marshaled, err := enc.marshalJSON(val)
 	if err != nil {
 		return err
 	}
@@ -207,10 +534,27 @@ func (enc *textEncoder) AppendReflected(val interface{}) error {
 
 func (enc *textEncoder) AppendString(val string) {
 	enc.addElementSeparator()
-	enc.buf.AppendByte('"')
+	if enc.normalize {
+		val = enc.normForm.String(val)
+	}
+	suffix := ""
+	if i := enc.truncateStringIndex(val); i >= 0 {
+		val, suffix = val[:i], enc.truncSuffix
+	}
+	if len(enc.redactions) > 0 {
+		val = enc.redactString(val)
+	}
+	quote := enc.quoteMode == QuoteModeAlways || enc.stringNeedsQuotes(val) ||
+		(suffix != "" && enc.stringNeedsQuotes(suffix))
+	if quote {
+		enc.buf.AppendByte(enc.quoteChar())
+	}
 	enc.safeAddString(val)
+	enc.safeAddString(suffix)
 	//enc.buf.AppendString(val)
-	enc.buf.AppendByte('"')
+	if quote {
+		enc.buf.AppendByte(enc.quoteChar())
+	}
 }
 
 func (enc *textEncoder) AppendTimeLayout(time time.Time, layout string) {
@@ -226,13 +570,17 @@ func (enc *textEncoder) AppendTime(val time.Time) {
 	if cur == enc.buf.Len() {
 		// User-supplied EncodeTime is a no-op. Fall back to nanos since epoch to keep
 		// output JSON valid.
-		enc.AppendInt64(val.UnixNano())
+		enc.appendDecimal(val.UnixNano())
 	}
 }
 
 func (enc *textEncoder) AppendUint64(val uint64) {
 	enc.addElementSeparator()
-	enc.buf.AppendUint(val)
+	if enc.intEncoding == IntDecimal {
+		enc.buf.AppendUint(val)
+		return
+	}
+	enc.appendUintDigits(val)
 }
 
 func (enc *textEncoder) AddComplex64(k string, v complex64) { enc.AddComplex128(k, complex128(v)) }
@@ -259,28 +607,112 @@ func (enc *textEncoder) AppendUint16(v uint16)              { enc.AppendUint64(u
 func (enc *textEncoder) AppendUint8(v uint8)                { enc.AppendUint64(uint64(v)) }
 func (enc *textEncoder) AppendUintptr(v uintptr)            { enc.AppendUint64(uint64(v)) }
 
+// Clone shares the fields added so far with the clone instead of copying
+// them, so that cloning an encoder holding many fields is cheap.
 func (enc *textEncoder) Clone() zapcore.Encoder {
-	clone := enc.clone()
-	clone.buf.Write(enc.buf.Bytes())
+	return enc.cloneContext(bufferPool.getKept())
+}
+
+// cloneContext returns a clone of enc writing to buf that shares the fields
+// added to enc.
+func (enc *textEncoder) cloneContext(buf *buffer.Buffer) *textEncoder {
+	clone := enc.cloneTo(buf)
+	clone.shared = enc.shared[:len(enc.shared):len(enc.shared)]
+	if n := enc.buf.Len(); n > 0 {
+		clone.shared = append(clone.shared, enc.buf.Bytes()[:n:n])
+	}
+	clone.spans = enc.spans[:len(enc.spans):len(enc.spans)]
 	return clone
 }
 
+// context returns the fields added to enc, shared or not.
+func (enc *textEncoder) context() []byte {
+	if len(enc.shared) == 0 {
+		return enc.buf.Bytes()
+	}
+	ctx := make([]byte, 0, enc.contextLen())
+	for _, part := range enc.shared {
+		ctx = append(ctx, part...)
+	}
+	return append(ctx, enc.buf.Bytes()...)
+}
+
+// contextLen is equivalent of len(enc.context()).
+func (enc *textEncoder) contextLen() int {
+	n := enc.buf.Len()
+	for _, part := range enc.shared {
+		n += len(part)
+	}
+	return n
+}
+
+// contextString is equivalent of string(enc.context()), with one
+// allocation.
+func (enc *textEncoder) contextString() string {
+	if len(enc.shared) == 0 {
+		return string(enc.buf.Bytes())
+	}
+	var sb strings.Builder
+	sb.Grow(enc.contextLen())
+	for _, part := range enc.shared {
+		sb.Write(part)
+	}
+	sb.Write(enc.buf.Bytes())
+	return sb.String()
+}
+
+// clone returns a copy of enc, without the fields added to it, writing to a
+// buffer taken from the pool.
 func (enc *textEncoder) clone() *textEncoder {
+	return enc.cloneTo(enc.buffers().Get())
+}
+
+// buffers returns the pool the buffers of entries are taken from.
+func (enc *textEncoder) buffers() *textBufferPool {
+	if enc.pool != nil {
+		return enc.pool
+	}
+	return bufferPool
+}
+
+func (enc *textEncoder) cloneTo(buf *buffer.Buffer) *textEncoder {
 	clone := getTextEncoder()
-	clone.EncoderConfig = enc.EncoderConfig
-	clone.buf = bufferPool.Get()
-	clone.separator = enc.separator
+	*clone = *enc
+	clone.buf = buf
+	clone.shared = nil
+	clone.fieldStart, clone.elemStart = 0, 0
+	clone.spans = nil
+	// Cap the copied slices so that appending to either encoder reallocates
+	// instead of overwriting the other.
+	clone.namespaces = enc.namespaces[:len(enc.namespaces):len(enc.namespaces)]
+	clone.conditional = enc.conditional[:len(enc.conditional):len(enc.conditional)]
 	return clone
 }
 
 func (enc *textEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	enc.countLevel(ent.Level)
+	if len(enc.conditional) > 0 {
+		return enc.encodeConditionalEntry(ent, fields)
+	}
+	fields = enc.extractContexts(fields)
+	fields = enc.hookFields(ent, enc.evalLazy(filterConditional(ent.Level, fields)))
+	if enc.logfmt {
+		return enc.encodeLogfmtEntry(ent, fields)
+	}
 	final := enc.clone()
 
 	arr := getSliceEncoder()
 	if enc.TimeKey != "" && enc.EncodeTime != nil {
 		enc.EncodeTime(ent.Time, arr)
+		if enc.timeColor != "" {
+			for i := range arr.elems {
+				arr.elems[i] = enc.timeColor + fmt.Sprint(arr.elems[i]) + ColorReset
+			}
+		}
 	}
+	levelIdx := -1
 	if enc.LevelKey != "" && enc.EncodeLevel != nil {
+		levelIdx = len(arr.elems)
 		enc.EncodeLevel(ent.Level, arr)
 	}
 	if ent.LoggerName != "" && enc.NameKey != "" {
@@ -302,69 +734,187 @@ func (enc *textEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (
 			arr.AppendString(ent.Caller.Function)
 		}
 	}
-	if enc.buf.Len() > 0 {
-		arr.AppendByteString(enc.buf.Bytes())
+	// The fields added to enc are encoded already, and written as they are.
+	ctxIdx := -1
+	if enc.dedup != DeduplicateNone {
+		var ctx []byte
+		ctx, fields = enc.deduplicate(fields)
+		if len(ctx) > 0 {
+			ctxIdx = len(arr.elems)
+			arr.AppendByteString(ctx)
+		}
+	} else if len(enc.shared) > 0 || enc.buf.Len() > 0 {
+		ctxIdx = len(arr.elems)
+		arr.AppendString(enc.contextString())
 	}
 	if final.MessageKey != "" {
-		arr.AppendString(ent.Message)
+		if color, ok := enc.messageColors[ent.Level]; ok {
+			arr.AppendString(color + ent.Message + ColorReset)
+		} else {
+			arr.AppendString(ent.Message)
+		}
 	}
+	final.buf.AppendString(enc.linePrefix)
+	final.fieldStart = final.buf.Len()
 	for i := range arr.elems {
 		if i > 0 {
 			final.buf.AppendString(enc.separator)
 		}
-		fmt.Fprint(final.buf, arr.elems[i])
+		switch elem := arr.elems[i].(type) {
+		case float64:
+			// Write epoch times in full rather than in exponent form.
+			final.buf.AppendFloat(elem, 64)
+		case float32:
+			final.buf.AppendFloat(float64(elem), 32)
+		case string:
+			if i == ctxIdx {
+				final.buf.AppendString(elem)
+			} else {
+				final.addHeaderString(elem)
+			}
+		default:
+			fmt.Fprint(final.buf, elem)
+		}
 
-		// Align level
-		if i == 1 {
-			if ent.Level == zapcore.InfoLevel || ent.Level == zapcore.WarnLevel {
+		// Align the four-letter levels with the others, unless the level
+		// encoder pads them already.
+		if i == levelIdx && (ent.Level == zapcore.InfoLevel || ent.Level == zapcore.WarnLevel) {
+			if utf8.RuneCountInString(stripANSI(fmt.Sprint(arr.elems[i]))) == 4 {
 				final.buf.AppendByte(' ')
 			}
 		}
 	}
 	putSliceEncoder(arr)
 
-	addFields(final, fields)
+	final.addFields(enc.orderFields(fields))
 
 	// If there's no stacktrace key, honor that; this allows users to force
 	// single-line output.
 	if ent.Stack != "" && final.StacktraceKey != "" {
 		final.buf.AppendByte('\n')
-		final.buf.AppendString(ent.Stack)
+		final.buf.AppendString(enc.formatStacktrace(ent.Stack))
 	}
-	if final.LineEnding != "" {
-		final.buf.AppendString(final.LineEnding)
-	} else {
-		final.buf.AppendString(zapcore.DefaultLineEnding)
+	return final.endEntry(), nil
+}
+
+// endEntry writes the line ending of an entry encoded by final, and hands
+// its buffer over to the caller.
+func (final *textEncoder) endEntry() *buffer.Buffer {
+	if !final.noLineEnding {
+		if final.LineEnding != "" {
+			final.buf.AppendString(final.LineEnding)
+		} else {
+			final.buf.AppendString(zapcore.DefaultLineEnding)
+		}
 	}
 
 	ret := final.buf
+	final.buffers().handOff()
 	putTextEncoder(final)
-	return ret, nil
+	return ret
 }
 
 func (enc *textEncoder) truncate() {
 	enc.buf.Reset()
+	enc.shared = nil
 }
 
-func (enc *textEncoder) addKey(key string) {
-	if enc.buf.Len() > 0 {
+// addKey writes key and its delimiter, and reports whether the value of the
+// field should follow. The mask is written instead if key is sensitive, see
+// WithSensitiveKeys, and the field is complete.
+func (enc *textEncoder) addKey(key string) bool {
+	fullKey := enc.namespacedKey(key)
+	enc.recordField(fullKey)
+	if enc.keySanitizer != nil {
+		fullKey = enc.keySanitizer.Sanitize(fullKey)
+	}
+	if enc.buf.Len() > enc.fieldStart || (enc.buf.Len() == 0 && len(enc.shared) > 0) {
 		enc.buf.AppendString(enc.separator)
 	}
-	enc.safeAddString(key)
-	enc.buf.AppendByte('=')
+	if color, ok := enc.keyColors[key]; ok {
+		enc.buf.AppendString(color)
+	}
+	if enc.logfmt {
+		enc.buf.AppendString(logfmtKey(fullKey))
+	} else {
+		enc.addEscapedKey(fullKey)
+	}
+	enc.buf.AppendString(enc.keyDelimiter())
+	enc.elemStart = enc.buf.Len()
+	if enc.sensitiveKeys.match(key) {
+		enc.AppendString(enc.mask)
+		enc.closeKey(key)
+		return false
+	}
+	return true
+}
+
+// closeKey ends the field started by addKey, once its value is written.
+func (enc *textEncoder) closeKey(key string) {
+	if _, ok := enc.keyColors[key]; ok {
+		enc.buf.AppendString(ColorReset)
+	}
+}
+
+// keyDelimiter returns the string written between a key and its value.
+func (enc *textEncoder) keyDelimiter() string {
+	if enc.keyDelim == "" {
+		return "="
+	}
+	return enc.keyDelim
+}
+
+// quoteChar returns the character that quoted strings are wrapped in.
+func (enc *textEncoder) quoteChar() byte {
+	if enc.quote == 0 {
+		return '"'
+	}
+	return enc.quote
+}
+
+// arrayDelimiters returns the strings written around the elements of an
+// array.
+func (enc *textEncoder) arrayDelimiters() (begin, end string) {
+	if enc.arrayBegin == "" && enc.arrayEnd == "" {
+		return "[", "]"
+	}
+	return enc.arrayBegin, enc.arrayEnd
 }
 
 func (enc *textEncoder) addElementSeparator() {
-	last := enc.buf.Len() - 1
-	if last < 0 {
+	if enc.buf.Len() <= enc.elemStart {
 		return
 	}
-	switch enc.buf.Bytes()[last] {
-	case '{', '[', '=', ',':
+	if enc.arraySep == "" {
+		enc.buf.AppendByte(',')
 		return
+	}
+	enc.buf.AppendString(enc.arraySep)
+}
+
+// appendUintDigits appends val with the prefix and base of the configured
+// IntEncoding.
+func (enc *textEncoder) appendUintDigits(val uint64) {
+	var b [66]byte
+	digits := b[:0]
+	switch enc.intEncoding {
+	case IntHex:
+		digits = strconv.AppendUint(append(digits, "0x"...), val, 16)
+	case IntHexUpper:
+		digits = strconv.AppendUint(append(digits, "0x"...), val, 16)
+		for i := 2; i < len(digits); i++ {
+			if 'a' <= digits[i] && digits[i] <= 'f' {
+				digits[i] -= 'a' - 'A'
+			}
+		}
+	case IntOctal:
+		digits = strconv.AppendUint(append(digits, "0o"...), val, 8)
+	case IntBinary:
+		digits = strconv.AppendUint(append(digits, "0b"...), val, 2)
 	default:
-		enc.buf.AppendByte(',')
+		digits = strconv.AppendUint(digits, val, 10)
 	}
+	enc.buf.Write(digits)
 }
 
 func (enc *textEncoder) appendFloat(val float64, bitSize int) {
@@ -376,9 +926,154 @@ func (enc *textEncoder) appendFloat(val float64, bitSize int) {
 		enc.buf.AppendString(`+Inf`)
 	case math.IsInf(val, -1):
 		enc.buf.AppendString(`-Inf`)
-	default:
+	case enc.floatFormat == FloatFormatDecimal && !enc.fixedFloatPrecision:
 		enc.buf.AppendFloat(val, bitSize)
+	default:
+		prec := -1
+		if enc.fixedFloatPrecision {
+			prec = enc.floatPrecision
+		}
+		var b [32]byte
+		enc.buf.Write(strconv.AppendFloat(b[:0], val, enc.floatFormat.verb(), prec, bitSize))
+	}
+}
+
+// truncateStringIndex returns the byte offset at which s has to be cut to
+// keep maxValueLen runes and at most maxValueBytes bytes, or -1 if s doesn't
+// need truncating. Each invalid UTF-8 byte counts as one rune, as it's
+// replaced by one \ufffd when escaped.
+func (enc *textEncoder) truncateStringIndex(s string) int {
+	cut := -1
+	if enc.maxValueLen > 0 && len(s) > enc.maxValueLen {
+		n := 0
+		for i := 0; i < len(s); n++ {
+			if n == enc.maxValueLen {
+				cut = i
+				break
+			}
+			_, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+		}
+	}
+	if enc.maxValueBytes > 0 && len(s) > enc.maxValueBytes {
+		i := runeStartInString(s, enc.maxValueBytes)
+		if cut < 0 || i < cut {
+			cut = i
+		}
+	}
+	return cut
+}
+
+// truncateByteStringIndex is equivalent of truncateStringIndex(string(s)) for s []byte.
+func (enc *textEncoder) truncateByteStringIndex(s []byte) int {
+	cut := -1
+	if enc.maxValueLen > 0 && len(s) > enc.maxValueLen {
+		n := 0
+		for i := 0; i < len(s); n++ {
+			if n == enc.maxValueLen {
+				cut = i
+				break
+			}
+			_, size := utf8.DecodeRune(s[i:])
+			i += size
+		}
+	}
+	if enc.maxValueBytes > 0 && len(s) > enc.maxValueBytes {
+		i := runeStart(s, enc.maxValueBytes)
+		if cut < 0 || i < cut {
+			cut = i
+		}
+	}
+	return cut
+}
+
+// runeStartInString moves i back to the start of the rune it falls in, so
+// that cutting s at i doesn't split a valid multi-byte rune. Invalid bytes
+// are left as they are, each being a rune of its own.
+func runeStartInString(s string, i int) int {
+	for j := i - 1; j >= 0 && j > i-utf8.UTFMax; j-- {
+		if utf8.RuneStart(s[j]) {
+			if r, size := utf8.DecodeRuneInString(s[j:]); r != utf8.RuneError && j+size > i {
+				return j
+			}
+			break
+		}
+	}
+	return i
+}
+
+// runeStart is equivalent of runeStartInString(string(s), i) for s []byte.
+func runeStart(s []byte, i int) int {
+	for j := i - 1; j >= 0 && j > i-utf8.UTFMax; j-- {
+		if utf8.RuneStart(s[j]) {
+			if r, size := utf8.DecodeRune(s[j:]); r != utf8.RuneError && j+size > i {
+				return j
+			}
+			break
+		}
+	}
+	return i
+}
+
+// stringNeedsQuotes reports whether s has to be quoted in QuoteModeAuto: it
+// is empty, contains whitespace, the field separator or a key delimiter, or
+// contains a character that is escaped or could be read as array or object
+// syntax. With WithASCIIOnly, that includes every non-ASCII character.
+func (enc *textEncoder) stringNeedsQuotes(s string) bool {
+	if s == "" || strings.Contains(s, enc.keyDelimiter()) {
+		return true
+	}
+	if enc.separator != "" && strings.Contains(s, enc.separator) {
+		return true
+	}
+	for i := 0; i < len(s); {
+		if s[i] < utf8.RuneSelf {
+			if needsQuotesASCII(s[i], enc.quoteChar()) {
+				return true
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if enc.asciiOnly || needsQuotesRune(r, size) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// byteStringNeedsQuotes is no-alloc equivalent of stringNeedsQuotes(string(s)) for s []byte.
+func (enc *textEncoder) byteStringNeedsQuotes(s []byte) bool {
+	if len(s) == 0 || bytes.Contains(s, []byte(enc.keyDelimiter())) {
+		return true
+	}
+	if enc.separator != "" && bytes.Contains(s, []byte(enc.separator)) {
+		return true
 	}
+	for i := 0; i < len(s); {
+		if s[i] < utf8.RuneSelf {
+			if needsQuotesASCII(s[i], enc.quoteChar()) {
+				return true
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(s[i:])
+		if enc.asciiOnly || needsQuotesRune(r, size) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+func needsQuotesASCII(b, quote byte) bool {
+	return b <= ' ' || b == quote || strings.IndexByte(`"\=,[]{}`, b) >= 0
+}
+
+func needsQuotesRune(r rune, size int) bool {
+	return (r == utf8.RuneError && size == 1) || unicode.IsSpace(r)
 }
 
 // safeAddString JSON-escapes a string and appends it to the internal buffer.
@@ -395,6 +1090,38 @@ func (enc *textEncoder) safeAddString(s string) {
 			i++
 			continue
 		}
+		if enc.asciiOnly {
+			enc.addRuneEscape(r)
+			i += size
+			continue
+		}
+		enc.buf.AppendString(s[i : i+size])
+		i += size
+	}
+}
+
+// addHeaderString writes a header element, such as the message, as it is
+// but for line breaks, other control characters and invalid UTF-8, which
+// are escaped as in quoted strings so that the entry stays on one line.
+// Tabs and the escape character starting color codes are left alone.
+func (enc *textEncoder) addHeaderString(s string) {
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < 0x20 && b != '\t' && b != '\x1b' {
+			enc.tryAddRuneSelf(b)
+			i++
+			continue
+		}
+		if b < utf8.RuneSelf {
+			enc.buf.AppendByte(b)
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if enc.tryAddRuneError(r, size) {
+			i++
+			continue
+		}
 		enc.buf.AppendString(s[i : i+size])
 		i += size
 	}
@@ -412,6 +1139,11 @@ func (enc *textEncoder) safeAddByteString(s []byte) {
 			i++
 			continue
 		}
+		if enc.asciiOnly {
+			enc.addRuneEscape(r)
+			i += size
+			continue
+		}
 		enc.buf.Write(s[i : i+size])
 		i += size
 	}
@@ -422,12 +1154,20 @@ func (enc *textEncoder) tryAddRuneSelf(b byte) bool {
 	if b >= utf8.RuneSelf {
 		return false
 	}
-	if 0x20 <= b && b != '\\' && b != '"' {
+	quote := enc.quoteChar()
+	if 0x20 <= b && b != '\\' && b != quote {
 		enc.buf.AppendByte(b)
 		return true
 	}
 	switch b {
-	case '\\', '"':
+	case quote:
+		if enc.escapeStyle == EscapeStyleDouble {
+			enc.buf.AppendByte(b)
+		} else {
+			enc.buf.AppendByte('\\')
+		}
+		enc.buf.AppendByte(b)
+	case '\\':
 		enc.buf.AppendByte('\\')
 		enc.buf.AppendByte(b)
 	case '\n':
@@ -448,16 +1188,87 @@ func (enc *textEncoder) tryAddRuneSelf(b byte) bool {
 	return true
 }
 
+// addRuneEscape appends r as \uXXXX, or as \UXXXXXXXX outside the Basic
+// Multilingual Plane.
+func (enc *textEncoder) addRuneEscape(r rune) {
+	prefix, digits := `\u`, 4
+	if r > 0xFFFF {
+		prefix, digits = `\U`, 8
+	}
+	enc.buf.AppendString(prefix)
+	for shift := 4 * (digits - 1); shift >= 0; shift -= 4 {
+		enc.buf.AppendByte(_hex[r>>uint(shift)&0xF])
+	}
+}
+
 func (enc *textEncoder) tryAddRuneError(r rune, size int) bool {
 	if r == utf8.RuneError && size == 1 {
-		enc.buf.AppendString(`\ufffd`)
+		if enc.replaceInvalid {
+			enc.buf.AppendString(enc.invalidReplacement)
+		} else {
+			enc.buf.AppendString(`\ufffd`)
+		}
 		return true
 	}
 	return false
 }
 
-func addFields(enc zapcore.ObjectEncoder, fields []zapcore.Field) {
+func (enc *textEncoder) addFields(fields []zapcore.Field) {
+	added, dropped := 0, 0
 	for i := range fields {
+		if fields[i].Type == zapcore.SkipType {
+			continue
+		}
+		if enc.maxFields > 0 && added == enc.maxFields {
+			dropped++
+			continue
+		}
+		added++
+		if fields[i].Type == zapcore.ErrorType {
+			enc.addErrorField(fields[i])
+			continue
+		}
 		fields[i].AddTo(enc)
 	}
+	if dropped > 0 && enc.addKey("fields_dropped") {
+		enc.appendDecimal(int64(dropped))
+		enc.closeKey("fields_dropped")
+	}
+}
+
+// EncodeEntryString encodes an entry like EncodeEntry and returns it as a
+// string, freeing the buffer.
+func (enc *textEncoder) EncodeEntryString(ent zapcore.Entry, fields []zapcore.Field) (string, error) {
+	buf, err := enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return "", err
+	}
+	s := buf.String()
+	buf.Free()
+	return s, nil
+}
+
+// writeBufferPool hands out the buffers of EncodeEntryTo, which are kept
+// apart from those of EncodeEntry and its statistics.
+var writeBufferPool = newBufferPool(0)
+
+// EncodeEntryTo encodes an entry like EncodeEntry and writes it to w in a
+// single Write call, so that the caller has no buffer to free. The entry is
+// encoded into a buffer from a pool of its own rather than the one of
+// EncodeEntry, see GetBufferPoolStats.
+func (enc *textEncoder) EncodeEntryTo(w io.Writer, ent zapcore.Entry, fields []zapcore.Field) error {
+	to := getTextEncoder()
+	*to = *enc
+	to.pool = writeBufferPool
+	buf, err := to.EncodeEntry(ent, fields)
+	putTextEncoder(to)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+	n, err := w.Write(buf.Bytes())
+	if err == nil && n < buf.Len() {
+		err = io.ErrShortWrite
+	}
+	return err
 }
@@ -1,8 +1,12 @@
 package zaptextencoder
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -52,3 +56,174 @@ func BenchmarkText(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkTextLargeEntry(b *testing.B) {
+	for _, size := range []int{0, 16 << 10} {
+		b.Run(fmt.Sprintf("initial size %d", size), func(b *testing.B) {
+			SetBufferPoolInitialSize(size)
+			defer SetBufferPoolInitialSize(0)
+
+			enc := NewTextEncoder(humanEncoderConfig())
+			fields := make([]zapcore.Field, 0, 64)
+			for i := 0; i < cap(fields); i++ {
+				fields = append(fields, zap.String(fmt.Sprintf("field%d", i), strings.Repeat("x", 128)))
+			}
+			ent := zapcore.Entry{Message: "fake", Level: zapcore.DebugLevel}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// Leave the buffer to the garbage collector instead of
+				// freeing it, so that every entry gets a fresh one.
+				_, _ = enc.EncodeEntry(ent, fields)
+			}
+		})
+	}
+}
+
+func BenchmarkTextKeyCache(b *testing.B) {
+	ent := zapcore.Entry{Message: "fake", Level: zapcore.DebugLevel}
+	for _, keys := range []struct {
+		desc   string
+		fields []zapcore.Field
+	}{
+		{"ascii", []zapcore.Field{
+			zap.Int("request_id", 1),
+			zap.Int("http.method", 2),
+			zap.Int("http.status_code", 3),
+			zap.Int("user_agent", 4),
+			zap.Int("cache_hit", 5),
+		}},
+		{"escaped", []zapcore.Field{
+			zap.Int("идентификатор\tзапроса", 1),
+			zap.Int("\"метод\" запроса", 2),
+			zap.Int("код\nответа", 3),
+			zap.Int("ユーザーエージェント", 4),
+			zap.Int("キャッシュ\x01", 5),
+		}},
+	} {
+		for _, tt := range []struct {
+			desc string
+			opts []Option
+		}{
+			{"no cache", nil},
+			{"cache", []Option{WithKeyCache(&KeyCache{})}},
+		} {
+			b.Run(keys.desc+"/"+tt.desc, func(b *testing.B) {
+				enc := NewTextEncoder(humanEncoderConfig(), tt.opts...)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					buf, _ := enc.EncodeEntry(ent, keys.fields)
+					buf.Free()
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkTextBoolsAndInts(b *testing.B) {
+	fields := make([]zapcore.Field, 0, 10)
+	for i := 0; i < cap(fields)/2; i++ {
+		fields = append(fields, zap.Bool(fmt.Sprintf("bool%d", i), i%2 == 0), zap.Int64(fmt.Sprintf("int%d", i), int64(i)<<40))
+	}
+	enc := NewTextEncoder(humanEncoderConfig())
+	ent := zapcore.Entry{Message: "fake", Level: zapcore.DebugLevel}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf, _ := enc.EncodeEntry(ent, fields)
+		buf.Free()
+	}
+}
+
+func BenchmarkTextClone(b *testing.B) {
+	for _, n := range []int{1, 100} {
+		enc := NewTextEncoder(humanEncoderConfig())
+		for i := 0; i < n; i++ {
+			enc.AddString(fmt.Sprintf("field%d", i), strings.Repeat("x", 32))
+		}
+		b.Run(fmt.Sprintf("%d fields", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				enc.Clone()
+			}
+		})
+	}
+}
+
+func BenchmarkTextClonedEntry(b *testing.B) {
+	parent := NewTextEncoder(humanEncoderConfig())
+	parent.AddString("service", "api")
+	child := parent.Clone()
+	child.AddString("request_id", "abc")
+	ent := zapcore.Entry{Message: "fake", Level: zapcore.DebugLevel}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf, _ := child.EncodeEntry(ent, nil)
+		buf.Free()
+	}
+}
+
+// diverseFields returns n fields cycling through strings, ints, bools,
+// floats and errors.
+func diverseFields(n int) []zapcore.Field {
+	fields := make([]zapcore.Field, n)
+	for i := range fields {
+		key := fmt.Sprintf("field%d", i)
+		switch i % 5 {
+		case 0:
+			fields[i] = zap.String(key, "some value")
+		case 1:
+			fields[i] = zap.Int(key, i*1000)
+		case 2:
+			fields[i] = zap.Bool(key, i%2 == 0)
+		case 3:
+			fields[i] = zap.Float64(key, float64(i)/3)
+		default:
+			fields[i] = zap.NamedError(key, errors.New("connection refused"))
+		}
+	}
+	return fields
+}
+
+func benchmarkEncodeEntry(b *testing.B, n int) {
+	enc := NewTextEncoder(humanEncoderConfig())
+	fields := diverseFields(n)
+	ent := zapcore.Entry{Message: "fake", Level: zapcore.DebugLevel}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf, _ := enc.EncodeEntry(ent, fields)
+		buf.Free()
+	}
+}
+
+func BenchmarkTextEncodeEntry1Field(b *testing.B)    { benchmarkEncodeEntry(b, 1) }
+func BenchmarkTextEncodeEntry10Fields(b *testing.B)  { benchmarkEncodeEntry(b, 10) }
+func BenchmarkTextEncodeEntry100Fields(b *testing.B) { benchmarkEncodeEntry(b, 100) }
+
+func BenchmarkTextSafeAddString(b *testing.B) {
+	for _, tt := range []struct {
+		desc string
+		s    string
+	}{
+		{"shortest", "a"},
+		{"medium", "a medium \"quoted\" string with\tsome escapes"},
+		{"long", strings.Repeat("a long string with ünïcödé and \"quotes\" ", 64)},
+	} {
+		b.Run(tt.desc, func(b *testing.B) {
+			enc := &textEncoder{buf: bufferPool.Get()}
+			b.ReportAllocs()
+			b.SetBytes(int64(len(tt.s)))
+			for i := 0; i < b.N; i++ {
+				enc.buf.Reset()
+				enc.safeAddString(tt.s)
+			}
+		})
+	}
+}
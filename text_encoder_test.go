@@ -3,10 +3,12 @@ package zaptextencoder
 import (
 	"errors"
 	"math"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -94,6 +96,11 @@ func TestTextEncodeEntry(t *testing.T) {
 				assert.Equal(t, tt.expected, buf.String(), "Incorrect encoded text entry.")
 			}
 			buf.Free()
+
+			str, err := enc.(TextEncoder).EncodeEntryString(tt.ent, tt.fields)
+			if assert.NoError(t, err, "Unexpected text encoding error.") {
+				assert.Equal(t, tt.expected, str, "Expected EncodeEntryString to match EncodeEntry.")
+			}
 		})
 	}
 }
@@ -153,6 +160,129 @@ func TestTextClone(t *testing.T) {
 	assertText(t, `baz="bing"`, clone.(*textEncoder))
 }
 
+func TestTextCloneSharesFields(t *testing.T) {
+	ent := zapcore.Entry{Message: "msg"}
+	encode := func(enc zapcore.Encoder) string {
+		buf, err := enc.EncodeEntry(ent, []zapcore.Field{zap.Int("n", 1)})
+		require.NoError(t, err, "Unexpected error encoding entry.")
+		defer buf.Free()
+		return buf.String()
+	}
+
+	for _, tt := range []struct {
+		desc string
+		opts []Option
+		// key added to the grandchild
+		key string
+		// expected output of parent, child and grandchild
+		expected [3]string
+	}{
+		{
+			desc: "text",
+			key:  "d",
+			expected: [3]string{
+				"a=1  c=3  msg  n=1\n",
+				"a=1  b=2  msg  n=1\n",
+				"a=1  b=2  d=4  msg  n=1\n",
+			},
+		},
+		{
+			desc: "deduplicated",
+			key:  "a",
+			opts: []Option{WithDeduplicateStrategy(DeduplicateLastWins)},
+			expected: [3]string{
+				"a=1  c=3  msg  n=1\n",
+				"a=1  b=2  msg  n=1\n",
+				"b=2  a=4  msg  n=1\n",
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			parent := NewTextEncoder(zapcore.EncoderConfig{MessageKey: "msg"}, tt.opts...)
+			parent.AddInt("a", 1)
+			child := parent.Clone()
+			child.AddInt("b", 2)
+			// Adding to the parent after cloning it mustn't affect the child.
+			parent.AddInt("c", 3)
+			grandchild := child.Clone()
+			grandchild.AddInt(tt.key, 4)
+
+			assert.Equal(t, tt.expected[0], encode(parent), "Unexpected parent output.")
+			assert.Equal(t, tt.expected[1], encode(child), "Unexpected child output.")
+			assert.Equal(t, tt.expected[2], encode(grandchild), "Unexpected grandchild output.")
+		})
+	}
+
+	t.Run("concurrent", func(t *testing.T) {
+		// Clone is called concurrently by loggers sharing a parent, which
+		// the race detector checks.
+		parent := NewTextEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+		parent.AddInt("a", 1)
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				child := parent.Clone()
+				child.AddInt("b", 2)
+				assert.Equal(t, "a=1  b=2  msg  n=1\n", encode(child), "Unexpected child output.")
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestTextEncoderWithFields(t *testing.T) {
+	enc := NewTextEncoderWithFields(
+		zapcore.EncoderConfig{MessageKey: "msg"},
+		zap.String("service", "api"),
+		zap.String("env", "prod"),
+	)
+	encode := func(enc zapcore.Encoder, fields ...zapcore.Field) string {
+		buf, err := enc.EncodeEntry(zapcore.Entry{Message: "msg"}, fields)
+		require.NoError(t, err, "Unexpected error encoding entry.")
+		defer buf.Free()
+		return buf.String()
+	}
+
+	assert.Equal(t, "service=\"api\"  env=\"prod\"  msg  n=1\n", encode(enc, zap.Int("n", 1)), "Unexpected output.")
+
+	child := enc.Clone()
+	child.AddInt("a", 1)
+	grandchild := child.Clone()
+	grandchild.AddInt("b", 2)
+	assert.Equal(t, "service=\"api\"  env=\"prod\"  a=1  msg\n", encode(child), "Unexpected child output.")
+	assert.Equal(t, "service=\"api\"  env=\"prod\"  a=1  b=2  msg\n", encode(grandchild), "Unexpected grandchild output.")
+	assert.Equal(t, "service=\"api\"  env=\"prod\"  msg\n", encode(enc), "Cloning mustn't change the original encoder.")
+
+	assert.Equal(t, "msg\n", encode(NewTextEncoderWithFields(zapcore.EncoderConfig{MessageKey: "msg"})), "Unexpected output without fields.")
+}
+
+func TestTextHeaderEscaping(t *testing.T) {
+	tests := []struct {
+		desc     string
+		msg      string
+		expected string
+	}{
+		{"plain", `say "hi" \o/`, `say "hi" \o/`},
+		{"line breaks", "two\nlines\r", `two\nlines\r`},
+		{"tab", "a\tb", "a\tb"},
+		{"control characters", "\x00\x07", `\u0000\u0007`},
+		{"invalid UTF-8", "a\xffb", `a\ufffdb`},
+		{"color codes", ColorRed + "red" + ColorReset, ColorRed + "red" + ColorReset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewTextEncoder(zapcore.EncoderConfig{MessageKey: "msg", NameKey: "name"})
+			buf, err := enc.EncodeEntry(zapcore.Entry{LoggerName: tt.msg, Message: tt.msg}, nil)
+			require.NoError(t, err, "Unexpected error encoding entry.")
+			assert.Equal(t, tt.expected+"  "+tt.expected+"\n", buf.String(), "Unexpected header.")
+			buf.Free()
+		})
+	}
+}
+
 func TestTextEscaping(t *testing.T) {
 	enc := &textEncoder{buf: bufferPool.Get()}
 	// Test all the edge cases of JSON escaping directly.
@@ -203,6 +333,25 @@ func TestTextEscaping(t *testing.T) {
 			assertText(t, output, enc)
 		}
 	})
+
+	t.Run("InvalidUTF8Replacement", func(t *testing.T) {
+		replaced := map[string]string{
+			"?":      "foo???",
+			"":       "foo",
+			`\x`:     `foo\x\x\x`,
+			"\ufffd": "foo\ufffd\ufffd\ufffd",
+		}
+		for replacement, output := range replaced {
+			enc := &textEncoder{buf: bufferPool.Get()}
+			WithInvalidUTF8Replacement(replacement)(enc)
+			enc.safeAddString("foo\xed\xa0\x80")
+			assertText(t, output, enc)
+
+			enc.truncate()
+			enc.safeAddByteString([]byte("foo\xed\xa0\x80"))
+			assertText(t, output, enc)
+		}
+	})
 }
 
 func TestTextEncoderObjectFields(t *testing.T) {
@@ -232,6 +381,15 @@ func TestTextEncoderObjectFields(t *testing.T) {
 		{"float32", `k=NaN`, func(e zapcore.Encoder) { e.AddFloat32("k", float32(math.NaN())) }},
 		{"float32", `k=+Inf`, func(e zapcore.Encoder) { e.AddFloat32("k", float32(math.Inf(1))) }},
 		{"float32", `k=-Inf`, func(e zapcore.Encoder) { e.AddFloat32("k", float32(math.Inf(-1))) }},
+		{"float64 scientific", `k=1e+10`, func(e zapcore.Encoder) { scientific(e).AddFloat64("k", 1e10) }},
+		{"float64 scientific", `k=1.5e-07`, func(e zapcore.Encoder) { scientific(e).AddFloat64("k", 1.5e-7) }},
+		{"float64 scientific", `k=NaN`, func(e zapcore.Encoder) { scientific(e).AddFloat64("k", math.NaN()) }},
+		{"float64 scientific", `k=+Inf`, func(e zapcore.Encoder) { scientific(e).AddFloat64("k", math.Inf(1)) }},
+		{"float64 scientific", `k=-Inf`, func(e zapcore.Encoder) { scientific(e).AddFloat64("k", math.Inf(-1)) }},
+		{"float32 scientific", `k=1e+10`, func(e zapcore.Encoder) { scientific(e).AddFloat32("k", 1e10) }},
+		{"float64 shortest", `k=1e+21`, func(e zapcore.Encoder) { shortest(e).AddFloat64("k", 1e21) }},
+		{"float64 shortest", `k=3.14`, func(e zapcore.Encoder) { shortest(e).AddFloat64("k", 3.14) }},
+		{"float64 shortest", `k=NaN`, func(e zapcore.Encoder) { shortest(e).AddFloat64("k", math.NaN()) }},
 		{"int", `k=42`, func(e zapcore.Encoder) { e.AddInt("k", 42) }},
 		{"int64", `k=42`, func(e zapcore.Encoder) { e.AddInt64("k", 42) }},
 		{"int32", `k=42`, func(e zapcore.Encoder) { e.AddInt32("k", 42) }},
@@ -318,6 +476,16 @@ func TestTextEncoderArrays(t *testing.T) {
 				})), "Expected an error appending an array.")
 			},
 		},
+		{
+			desc:     "objects (error)",
+			expected: `[{name="a"},{name="a"}]`,
+			f: func(arr zapcore.ArrayEncoder) {
+				assert.Error(t, arr.AppendObject(zapcore.ObjectMarshalerFunc(func(inner zapcore.ObjectEncoder) error {
+					inner.AddString("name", "a")
+					return errors.New("fail")
+				})), "Expected an error appending an object.")
+			},
+		},
 		{
 			desc:     "reflect (success)",
 			expected: `[{"foo":5},{"foo":5}]`,
@@ -379,8 +547,37 @@ func assertOutput(t testing.TB, cfg zapcore.EncoderConfig, expected string, f fu
 	assert.Equal(t, expectedPrefix+expected, enc.buf.String(), "Unexpected encoder output after adding as a second field.")
 }
 
+// scientific switches e to FloatFormatScientific.
+func scientific(e zapcore.Encoder) zapcore.Encoder {
+	WithFloatFormat(FloatFormatScientific)(e.(*textEncoder))
+	return e
+}
+
+// shortest switches e to FloatFormatShortest.
+func shortest(e zapcore.Encoder) zapcore.Encoder {
+	WithFloatFormat(FloatFormatShortest)(e.(*textEncoder))
+	return e
+}
+
 type noJSON struct{}
 
 func (nj noJSON) MarshalJSON() ([]byte, error) {
 	return nil, errors.New("no")
 }
+
+func TestTextAddBoolAndIntDontAllocate(t *testing.T) {
+	enc := NewTextEncoder(_defaultEncoderConfig).(*textEncoder)
+	// Grow the buffer first, so that only the encoding itself is measured.
+	for i := 0; i < 64; i++ {
+		enc.AddInt64("k", math.MinInt64)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		enc.truncate()
+		for i := 0; i < 5; i++ {
+			enc.AddBool("bool", true)
+			enc.AddInt64("int64", math.MinInt64)
+			enc.AddInt("int", 42)
+		}
+	})
+	assert.Zero(t, allocs, "Expected AddBool and AddInt not to allocate.")
+}
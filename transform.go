@@ -0,0 +1,48 @@
+package zaptextencoder
+
+import "go.uber.org/zap/zapcore"
+
+// FieldTransformer returns the string to write in place of the value of a
+// string field added to a TransformingEncoder, and false to drop the field
+// instead. key is the key of the field, or that of its array for the
+// elements of arrays.
+type FieldTransformer func(key, value string) (string, bool)
+
+// TransformingEncoder is a zapcore.Encoder passing the values of the
+// string fields, added by logger.With or a logging call, through a
+// FieldTransformer, such as to truncate them, normalize their case or mask
+// PII in them:
+//
+//	enc = zaptextencoder.WrapWithTransformer(enc, func(key, value string) (string, bool) {
+//		if key == "email" {
+//			return "[EMAIL]", true
+//		}
+//		return value, true
+//	})
+//
+// The string fields of objects and the string elements of arrays are
+// transformed too.
+type TransformingEncoder struct {
+	*interceptingEncoder
+}
+
+// WrapWithTransformer returns a TransformingEncoder transforming the string
+// fields added to enc with t, or enc itself if t is nil.
+func WrapWithTransformer(enc zapcore.Encoder, t FieldTransformer) zapcore.Encoder {
+	if t == nil {
+		return enc
+	}
+	return &TransformingEncoder{newInterceptingEncoder(enc, func(f zapcore.Field) (zapcore.Field, bool) {
+		if f.Type != zapcore.StringType {
+			return f, true
+		}
+		var ok bool
+		f.String, ok = t(f.Key, f.String)
+		return f, ok
+	})}
+}
+
+// Clone returns a TransformingEncoder wrapping a clone of the encoder.
+func (e *TransformingEncoder) Clone() zapcore.Encoder {
+	return &TransformingEncoder{e.clone()}
+}
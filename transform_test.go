@@ -0,0 +1,40 @@
+package zaptextencoder
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWrapWithTransformer(t *testing.T) {
+	upper := func(key, value string) (string, bool) {
+		if key == "secret" {
+			return "", false
+		}
+		return strings.ToUpper(value), true
+	}
+	var out bytes.Buffer
+	enc := WrapWithTransformer(NewTextEncoder(_optionsEncoderConfig), upper)
+	logger := zap.New(zapcore.NewCore(enc, zapcore.AddSync(&out), zap.DebugLevel))
+
+	logger.With(zap.String("ctx", "with")).Info("lob law",
+		zap.String("greeting", "hello"),
+		zap.String("secret", "hunter2"),
+		zap.Strings("words", []string{"a", "b"}),
+		zap.Int("n", 1),
+		zap.Object("obj", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("inner", "x")
+			return nil
+		})),
+	)
+	assert.Equal(t, "info   ctx=\"WITH\"  lob law  greeting=\"HELLO\"  words=[\"A\",\"B\"]  n=1  obj={inner=\"X\"}\n", out.String(),
+		"Unexpected transformed output.")
+
+	assert.IsType(t, &TransformingEncoder{}, enc.Clone(), "Expected clones to transform fields too.")
+	plain := NewTextEncoder(_optionsEncoderConfig)
+	assert.Equal(t, plain, WrapWithTransformer(plain, nil), "Expected no wrapper without a transformer.")
+}
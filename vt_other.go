@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package zaptextencoder
+
+// EnableWindowsVT is a no-op outside of Windows, where terminals render
+// ANSI escape sequences without any setup.
+func EnableWindowsVT() error {
+	return nil
+}
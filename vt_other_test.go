@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package zaptextencoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnableWindowsVTNoop(t *testing.T) {
+	assert.NoError(t, EnableWindowsVT(), "Expected EnableWindowsVT to be a no-op outside of Windows.")
+}
@@ -0,0 +1,31 @@
+//go:build windows
+// +build windows
+
+package zaptextencoder
+
+import (
+	"os"
+	"syscall"
+)
+
+const _enableVirtualTerminalProcessing = 0x0004
+
+var _procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// EnableWindowsVT turns on virtual terminal processing for the console
+// attached to os.Stdout, so that it renders ANSI escape sequences. It
+// returns an error if os.Stdout isn't a console or the mode can't be set.
+func EnableWindowsVT() error {
+	h := syscall.Handle(os.Stdout.Fd())
+	var mode uint32
+	if err := syscall.GetConsoleMode(h, &mode); err != nil {
+		return err
+	}
+	if mode&_enableVirtualTerminalProcessing != 0 {
+		return nil
+	}
+	if r, _, err := _procSetConsoleMode.Call(uintptr(h), uintptr(mode|_enableVirtualTerminalProcessing)); r == 0 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package zaptextencoder
+
+import (
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// fieldInterceptor is given every field added to an interceptingEncoder,
+// including the elements of arrays as fields keyed by their array, and
+// returns the field to add in its place, if any.
+type fieldInterceptor func(f zapcore.Field) (zapcore.Field, bool)
+
+// interceptingEncoder is a zapcore.Encoder passing the fields added to it,
+// and those of its entries, through intercept before adding them to enc.
+type interceptingEncoder struct {
+	interceptingObject
+	enc zapcore.Encoder
+}
+
+func newInterceptingEncoder(enc zapcore.Encoder, intercept fieldInterceptor) *interceptingEncoder {
+	return &interceptingEncoder{interceptingObject{enc, intercept}, enc}
+}
+
+func (e *interceptingEncoder) clone() *interceptingEncoder {
+	return newInterceptingEncoder(e.enc.Clone(), e.intercept)
+}
+
+func (e *interceptingEncoder) Clone() zapcore.Encoder {
+	return e.clone()
+}
+
+func (e *interceptingEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	out := make([]zapcore.Field, 0, len(fields))
+	for _, f := range fields {
+		if f, ok := e.intercept(f); ok {
+			out = append(out, wrapField(f, e.intercept))
+		}
+	}
+	return e.enc.EncodeEntry(ent, out)
+}
+
+// interceptingObject is a zapcore.ObjectEncoder passing the fields added to
+// it through intercept before adding them to ObjectEncoder.
+type interceptingObject struct {
+	zapcore.ObjectEncoder
+	intercept fieldInterceptor
+}
+
+// add intercepts f and adds what's left of it.
+func (e interceptingObject) add(f zapcore.Field) error {
+	f, ok := e.intercept(f)
+	if !ok {
+		return nil
+	}
+	f = wrapField(f, e.intercept)
+	switch f.Type {
+	case zapcore.ArrayMarshalerType:
+		return e.ObjectEncoder.AddArray(f.Key, f.Interface.(zapcore.ArrayMarshaler))
+	case zapcore.ObjectMarshalerType:
+		return e.ObjectEncoder.AddObject(f.Key, f.Interface.(zapcore.ObjectMarshaler))
+	case zapcore.ReflectType:
+		return e.ObjectEncoder.AddReflected(f.Key, f.Interface)
+	}
+	f.AddTo(e.ObjectEncoder)
+	return nil
+}
+
+// wrapField makes the fields and elements of an array or object field go
+// through intercept when they're added. The values of the fields the
+// encoder recognises are left alone.
+func wrapField(f zapcore.Field, intercept fieldInterceptor) zapcore.Field {
+	switch v := f.Interface.(type) {
+	case urlValue, conditionalValue:
+	case zapcore.ArrayMarshaler:
+		if f.Type == zapcore.ArrayMarshalerType {
+			f.Interface = interceptingArrayMarshaler{v, f.Key, intercept}
+		}
+	case zapcore.ObjectMarshaler:
+		if f.Type == zapcore.ObjectMarshalerType {
+			f.Interface = interceptingObjectMarshaler{v, intercept}
+		}
+	}
+	return f
+}
+
+func (e interceptingObject) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	return e.add(zap.Array(key, arr))
+}
+
+func (e interceptingObject) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	return e.add(zap.Object(key, obj))
+}
+
+func (e interceptingObject) AddReflected(key string, v interface{}) error {
+	return e.add(zap.Reflect(key, v))
+}
+
+func (e interceptingObject) AddBinary(key string, v []byte)          { e.add(zap.Binary(key, v)) }
+func (e interceptingObject) AddByteString(key string, v []byte)      { e.add(zap.ByteString(key, v)) }
+func (e interceptingObject) AddBool(key string, v bool)              { e.add(zap.Bool(key, v)) }
+func (e interceptingObject) AddComplex128(key string, v complex128)  { e.add(zap.Complex128(key, v)) }
+func (e interceptingObject) AddComplex64(key string, v complex64)    { e.add(zap.Complex64(key, v)) }
+func (e interceptingObject) AddDuration(key string, v time.Duration) { e.add(zap.Duration(key, v)) }
+func (e interceptingObject) AddFloat64(key string, v float64)        { e.add(zap.Float64(key, v)) }
+func (e interceptingObject) AddFloat32(key string, v float32)        { e.add(zap.Float32(key, v)) }
+func (e interceptingObject) AddInt(key string, v int)                { e.add(zap.Int(key, v)) }
+func (e interceptingObject) AddInt64(key string, v int64)            { e.add(zap.Int64(key, v)) }
+func (e interceptingObject) AddInt32(key string, v int32)            { e.add(zap.Int32(key, v)) }
+func (e interceptingObject) AddInt16(key string, v int16)            { e.add(zap.Int16(key, v)) }
+func (e interceptingObject) AddInt8(key string, v int8)              { e.add(zap.Int8(key, v)) }
+func (e interceptingObject) AddString(key, v string)                 { e.add(zap.String(key, v)) }
+func (e interceptingObject) AddTime(key string, v time.Time)         { e.add(zap.Time(key, v)) }
+func (e interceptingObject) AddUint(key string, v uint)              { e.add(zap.Uint(key, v)) }
+func (e interceptingObject) AddUint64(key string, v uint64)          { e.add(zap.Uint64(key, v)) }
+func (e interceptingObject) AddUint32(key string, v uint32)          { e.add(zap.Uint32(key, v)) }
+func (e interceptingObject) AddUint16(key string, v uint16)          { e.add(zap.Uint16(key, v)) }
+func (e interceptingObject) AddUint8(key string, v uint8)            { e.add(zap.Uint8(key, v)) }
+func (e interceptingObject) AddUintptr(key string, v uintptr)        { e.add(zap.Uintptr(key, v)) }
+func (e interceptingObject) OpenNamespace(key string)                { e.add(zap.Namespace(key)) }
+
+type interceptingObjectMarshaler struct {
+	obj       zapcore.ObjectMarshaler
+	intercept fieldInterceptor
+}
+
+func (m interceptingObjectMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return m.obj.MarshalLogObject(interceptingObject{enc, m.intercept})
+}
+
+type interceptingArrayMarshaler struct {
+	arr       zapcore.ArrayMarshaler
+	key       string
+	intercept fieldInterceptor
+}
+
+func (m interceptingArrayMarshaler) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return m.arr.MarshalLogArray(interceptingArray{enc, m.key, m.intercept})
+}
+
+// interceptingArray is a zapcore.ArrayEncoder passing its elements through
+// intercept, as fields keyed by the array, before appending them to
+// ArrayEncoder.
+type interceptingArray struct {
+	zapcore.ArrayEncoder
+	key       string
+	intercept fieldInterceptor
+}
+
+// append intercepts f and appends what's left of it.
+func (e interceptingArray) append(f zapcore.Field) error {
+	f, ok := e.intercept(f)
+	if !ok {
+		return nil
+	}
+	f = wrapField(f, e.intercept)
+	switch v := fieldValue(f).(type) {
+	case zapcore.ArrayMarshaler:
+		return e.ArrayEncoder.AppendArray(v)
+	case zapcore.ObjectMarshaler:
+		return e.ArrayEncoder.AppendObject(v)
+	case bool:
+		e.ArrayEncoder.AppendBool(v)
+	case []byte:
+		e.ArrayEncoder.AppendByteString(v)
+	case complex128:
+		e.ArrayEncoder.AppendComplex128(v)
+	case complex64:
+		e.ArrayEncoder.AppendComplex64(v)
+	case time.Duration:
+		e.ArrayEncoder.AppendDuration(v)
+	case float64:
+		e.ArrayEncoder.AppendFloat64(v)
+	case float32:
+		e.ArrayEncoder.AppendFloat32(v)
+	case int64:
+		e.ArrayEncoder.AppendInt64(v)
+	case int32:
+		e.ArrayEncoder.AppendInt32(v)
+	case int16:
+		e.ArrayEncoder.AppendInt16(v)
+	case int8:
+		e.ArrayEncoder.AppendInt8(v)
+	case string:
+		e.ArrayEncoder.AppendString(v)
+	case time.Time:
+		e.ArrayEncoder.AppendTime(v)
+	case uint64:
+		e.ArrayEncoder.AppendUint64(v)
+	case uint32:
+		e.ArrayEncoder.AppendUint32(v)
+	case uint16:
+		e.ArrayEncoder.AppendUint16(v)
+	case uint8:
+		e.ArrayEncoder.AppendUint8(v)
+	case uintptr:
+		e.ArrayEncoder.AppendUintptr(v)
+	default:
+		return e.ArrayEncoder.AppendReflected(v)
+	}
+	return nil
+}
+
+func (e interceptingArray) AppendArray(v zapcore.ArrayMarshaler) error {
+	return e.append(zap.Array(e.key, v))
+}
+
+func (e interceptingArray) AppendObject(v zapcore.ObjectMarshaler) error {
+	return e.append(zap.Object(e.key, v))
+}
+
+func (e interceptingArray) AppendReflected(v interface{}) error {
+	return e.append(zap.Reflect(e.key, v))
+}
+
+func (e interceptingArray) AppendBool(v bool)              { e.append(zap.Bool(e.key, v)) }
+func (e interceptingArray) AppendByteString(v []byte)      { e.append(zap.ByteString(e.key, v)) }
+func (e interceptingArray) AppendComplex128(v complex128)  { e.append(zap.Complex128(e.key, v)) }
+func (e interceptingArray) AppendComplex64(v complex64)    { e.append(zap.Complex64(e.key, v)) }
+func (e interceptingArray) AppendDuration(v time.Duration) { e.append(zap.Duration(e.key, v)) }
+func (e interceptingArray) AppendFloat64(v float64)        { e.append(zap.Float64(e.key, v)) }
+func (e interceptingArray) AppendFloat32(v float32)        { e.append(zap.Float32(e.key, v)) }
+func (e interceptingArray) AppendInt(v int)                { e.append(zap.Int(e.key, v)) }
+func (e interceptingArray) AppendInt64(v int64)            { e.append(zap.Int64(e.key, v)) }
+func (e interceptingArray) AppendInt32(v int32)            { e.append(zap.Int32(e.key, v)) }
+func (e interceptingArray) AppendInt16(v int16)            { e.append(zap.Int16(e.key, v)) }
+func (e interceptingArray) AppendInt8(v int8)              { e.append(zap.Int8(e.key, v)) }
+func (e interceptingArray) AppendString(v string)          { e.append(zap.String(e.key, v)) }
+func (e interceptingArray) AppendTime(v time.Time)         { e.append(zap.Time(e.key, v)) }
+func (e interceptingArray) AppendUint(v uint)              { e.append(zap.Uint(e.key, v)) }
+func (e interceptingArray) AppendUint64(v uint64)          { e.append(zap.Uint64(e.key, v)) }
+func (e interceptingArray) AppendUint32(v uint32)          { e.append(zap.Uint32(e.key, v)) }
+func (e interceptingArray) AppendUint16(v uint16)          { e.append(zap.Uint16(e.key, v)) }
+func (e interceptingArray) AppendUint8(v uint8)            { e.append(zap.Uint8(e.key, v)) }
+func (e interceptingArray) AppendUintptr(v uintptr)        { e.append(zap.Uintptr(e.key, v)) }
+
+// fieldValue returns the value of f as the type it was created from, such
+// as an int32 for an Int32Type field, or nil for a NamespaceType or
+// SkipType field.
+func fieldValue(f zapcore.Field) interface{} {
+	switch f.Type {
+	case zapcore.BinaryType, zapcore.ByteStringType:
+		return f.Interface.([]byte)
+	case zapcore.BoolType:
+		return f.Integer == 1
+	case zapcore.Complex128Type:
+		return f.Interface.(complex128)
+	case zapcore.Complex64Type:
+		return f.Interface.(complex64)
+	case zapcore.DurationType:
+		return time.Duration(f.Integer)
+	case zapcore.Float64Type:
+		return math.Float64frombits(uint64(f.Integer))
+	case zapcore.Float32Type:
+		return math.Float32frombits(uint32(f.Integer))
+	case zapcore.Int64Type:
+		return f.Integer
+	case zapcore.Int32Type:
+		return int32(f.Integer)
+	case zapcore.Int16Type:
+		return int16(f.Integer)
+	case zapcore.Int8Type:
+		return int8(f.Integer)
+	case zapcore.StringType:
+		return f.String
+	case zapcore.TimeType:
+		if loc, ok := f.Interface.(*time.Location); ok {
+			return time.Unix(0, f.Integer).In(loc)
+		}
+		return time.Unix(0, f.Integer)
+	case zapcore.Uint64Type:
+		return uint64(f.Integer)
+	case zapcore.Uint32Type:
+		return uint32(f.Integer)
+	case zapcore.Uint16Type:
+		return uint16(f.Integer)
+	case zapcore.Uint8Type:
+		return uint8(f.Integer)
+	case zapcore.UintptrType:
+		return uintptr(f.Integer)
+	case zapcore.NamespaceType, zapcore.SkipType:
+		return nil
+	}
+	// Arrays, objects, reflected values, Stringers, errors, and times
+	// outside of the range of TimeType.
+	return f.Interface
+}
@@ -0,0 +1,66 @@
+package zaptextencoder
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type shortWriter struct{}
+
+func (shortWriter) Write(p []byte) (int, error) { return len(p) / 2, nil }
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestEncodeEntryTo(t *testing.T) {
+	enc := NewTextEncoder(_optionsEncoderConfig).(TextEncoder)
+	enc.AddString("ctx", "with")
+	fields := []zapcore.Field{zap.String("so", "passes"), zap.Int("answer", 42)}
+
+	buf, err := enc.EncodeEntry(_optionsEntry, fields)
+	if !assert.NoError(t, err, "Unexpected text encoding error.") {
+		return
+	}
+	expected := buf.String()
+	buf.Free()
+
+	var out bytes.Buffer
+	stats := GetBufferPoolStats()
+	assert.NoError(t, enc.EncodeEntryTo(&out, _optionsEntry, fields), "Unexpected error writing the entry.")
+	assert.Equal(t, expected, out.String(), "Expected the same bytes as EncodeEntry.")
+	assert.Equal(t, stats, GetBufferPoolStats(), "Expected no buffer taken from the pool of EncodeEntry.")
+
+	assert.Equal(t, io.ErrShortWrite, enc.EncodeEntryTo(shortWriter{}, _optionsEntry, fields))
+	assert.EqualError(t, enc.EncodeEntryTo(failingWriter{}, _optionsEntry, fields), "disk full")
+}
+
+func TestEncodeEntryToScratchBuffers(t *testing.T) {
+	conditional := NewTextEncoder(_optionsEncoderConfig)
+	ConditionalField(zap.ErrorLevel, zap.String("body", "...")).AddTo(conditional)
+	tests := []struct {
+		desc     string
+		enc      zapcore.Encoder
+		expected string
+	}{
+		{"logfmt", NewLogfmtEncoder(_optionsEncoderConfig), `ids="[1,2]"`},
+		{"conditional", conditional, `body="..."`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			stats := GetBufferPoolStats()
+			err := tt.enc.(TextEncoder).EncodeEntryTo(&out, _optionsEntry, []zapcore.Field{zap.Ints("ids", []int{1, 2})})
+			assert.NoError(t, err, "Unexpected error writing the entry.")
+			assert.Contains(t, out.String(), tt.expected, "Unexpected entry.")
+			assert.Equal(t, stats, GetBufferPoolStats(), "Expected no buffer taken from the pool of EncodeEntry.")
+		})
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/hms58/zaptextencoder/zaptextotel
+
+go 1.16
+
+require (
+	github.com/hms58/zaptextencoder v0.0.0
+	github.com/stretchr/testify v1.7.0
+	go.opentelemetry.io/otel/trace v1.0.0
+	go.uber.org/zap v1.16.0
+)
+
+replace github.com/hms58/zaptextencoder => ../
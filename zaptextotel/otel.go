@@ -0,0 +1,31 @@
+// Package zaptextotel adds the trace context of OpenTelemetry to the
+// entries of zaptextencoder encoders.
+package zaptextotel
+
+import (
+	"context"
+
+	"github.com/hms58/zaptextencoder"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// OTelContextExtractor returns a ContextExtractor adding the trace_id and
+// span_id of the span in a context, if it is valid, such as
+//
+//	enc := zaptextencoder.NewTextEncoder(cfg,
+//		zaptextencoder.WithContextExtractor(zaptextotel.OTelContextExtractor()))
+//	logger.Info("done", zaptextencoder.ContextField(ctx))
+func OTelContextExtractor() zaptextencoder.ContextExtractor {
+	return func(ctx context.Context) []zapcore.Field {
+		sc := trace.SpanFromContext(ctx).SpanContext()
+		if !sc.IsValid() {
+			return nil
+		}
+		return []zapcore.Field{
+			zap.String("trace_id", sc.TraceID().String()),
+			zap.String("span_id", sc.SpanID().String()),
+		}
+	}
+}
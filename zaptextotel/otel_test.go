@@ -0,0 +1,40 @@
+package zaptextotel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hms58/zaptextencoder"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestOTelContextExtractor(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	// A span of the noop tracer only carries the span context it is
+	// started in.
+	ctx, span := trace.NewNoopTracerProvider().Tracer("test").Start(trace.ContextWithSpanContext(context.Background(), sc), "op")
+	defer span.End()
+
+	cfg := zapcore.EncoderConfig{MessageKey: "M"}
+	enc := zaptextencoder.NewTextEncoder(cfg, zaptextencoder.WithContextExtractor(OTelContextExtractor())).(zaptextencoder.TextEncoder)
+
+	s, err := enc.EncodeEntryString(zapcore.Entry{Message: "lob law"}, []zapcore.Field{zaptextencoder.ContextField(ctx)})
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		assert.Equal(t, "lob law  trace_id=\"4bf92f3577b34da6a3ce929d0e0e4736\"  span_id=\"00f067aa0ba902b7\"\n", s)
+	}
+
+	s, err = enc.EncodeEntryString(zapcore.Entry{Message: "lob law"}, []zapcore.Field{
+		zaptextencoder.ContextField(context.Background()),
+		zap.Int("k", 1),
+	})
+	if assert.NoError(t, err, "Unexpected text encoding error.") {
+		assert.Equal(t, "lob law  k=1\n", s, "Expected no fields without a valid span.")
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/hms58/zaptextencoder/zaptextprom
+
+go 1.16
+
+require (
+	github.com/hms58/zaptextencoder v0.0.0
+	github.com/prometheus/client_golang v1.11.1
+	github.com/stretchr/testify v1.7.0
+	go.uber.org/zap v1.16.0
+)
+
+replace github.com/hms58/zaptextencoder => ../
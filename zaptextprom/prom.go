@@ -0,0 +1,47 @@
+// Package zaptextprom counts the entries of zaptextencoder encoders in
+// Prometheus metrics.
+package zaptextprom
+
+import (
+	"github.com/hms58/zaptextencoder"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithPrometheusCounters counts the entries of each level in the
+// log_entries_total counter of reg, or of prometheus.DefaultRegisterer if
+// reg is nil, with the level in the "level" label and labels as constant
+// labels, such as
+//
+//	enc := zaptextencoder.NewTextEncoder(cfg,
+//		zaptextprom.WithPrometheusCounters(nil, prometheus.Labels{"service": "api"}))
+//
+// Encoders registering the same counter share it. If it can't be
+// registered, entries are counted in a counter nobody gathers.
+func WithPrometheusCounters(reg prometheus.Registerer, labels prometheus.Labels) zaptextencoder.Option {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:        "log_entries_total",
+		Help:        "Number of log entries encoded, by level.",
+		ConstLabels: labels,
+	}, []string{"level"})
+	if err := reg.Register(vec); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if existing, ok := are.ExistingCollector.(*prometheus.CounterVec); ok {
+				vec = existing
+			}
+		}
+	}
+
+	var counters [zapcore.FatalLevel - zapcore.DebugLevel + 1]prometheus.Counter
+	for i := range counters {
+		counters[i] = vec.WithLabelValues((zapcore.DebugLevel + zapcore.Level(i)).String())
+	}
+	return zaptextencoder.WithLevelCounter(func(level zapcore.Level) {
+		if level >= zapcore.DebugLevel && level <= zapcore.FatalLevel {
+			counters[level-zapcore.DebugLevel].Inc()
+		}
+	})
+}
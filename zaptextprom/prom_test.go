@@ -0,0 +1,57 @@
+package zaptextprom
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/hms58/zaptextencoder"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// gatherCounts returns the log_entries_total counts of reg by level.
+func gatherCounts(t *testing.T, reg prometheus.Gatherer) map[string]float64 {
+	families, err := reg.Gather()
+	require.NoError(t, err, "Unexpected error gathering metrics.")
+	counts := make(map[string]float64)
+	for _, mf := range families {
+		if mf.GetName() != "log_entries_total" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			labels := make(map[string]string)
+			for _, lp := range m.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			assert.Equal(t, "api", labels["service"], "Expected the constant labels.")
+			counts[labels["level"]] = m.GetCounter().GetValue()
+		}
+	}
+	return counts
+}
+
+func TestWithPrometheusCounters(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	labels := prometheus.Labels{"service": "api"}
+	newLogger := func() *zap.Logger {
+		enc := zaptextencoder.NewTextEncoder(zapcore.EncoderConfig{MessageKey: "M"}, WithPrometheusCounters(reg, labels))
+		return zap.New(zapcore.NewCore(enc, zapcore.AddSync(ioutil.Discard), zap.InfoLevel))
+	}
+
+	logger := newLogger()
+	logger.Debug("dropped")
+	logger.Info("lob law")
+	logger.Info("lob law")
+	logger.Error("lob law")
+	counts := gatherCounts(t, reg)
+	assert.Equal(t, 2.0, counts["info"], "Unexpected info count.")
+	assert.Equal(t, 1.0, counts["error"], "Unexpected error count.")
+	assert.Equal(t, 0.0, counts["debug"], "Unexpected debug count.")
+
+	// A second encoder shares the registered counter.
+	newLogger().Error("lob law")
+	assert.Equal(t, 2.0, gatherCounts(t, reg)["error"], "Expected encoders to share the counter.")
+}
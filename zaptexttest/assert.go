@@ -0,0 +1,43 @@
+package zaptexttest
+
+import "testing"
+
+// AssertFieldInLog checks that the entry logLine, as written by the text
+// encoder, has the field key with the value expected, and reports whether
+// it does. Quoted values are compared unquoted, and objects and arrays as
+// they are written. The header elements are under LevelKey, MessageKey and
+// the other keys of this package.
+func AssertFieldInLog(t testing.TB, logLine, key, expected string) bool {
+	t.Helper()
+	fields, err := ParseTextLogLine(logLine)
+	if err != nil {
+		t.Errorf("Can't parse log line %q: %v", logLine, err)
+		return false
+	}
+	actual, ok := fields[key]
+	if !ok {
+		t.Errorf("Log line %q has no field %q.", logLine, key)
+		return false
+	}
+	if actual != expected {
+		t.Errorf("Field %q of log line %q is %q, expected %q.", key, logLine, actual, expected)
+		return false
+	}
+	return true
+}
+
+// AssertFieldAbsent checks that the entry logLine, as written by the text
+// encoder, doesn't have the field key, and reports whether it doesn't.
+func AssertFieldAbsent(t testing.TB, logLine, key string) bool {
+	t.Helper()
+	fields, err := ParseTextLogLine(logLine)
+	if err != nil {
+		t.Errorf("Can't parse log line %q: %v", logLine, err)
+		return false
+	}
+	if actual, ok := fields[key]; ok {
+		t.Errorf("Log line %q has field %q with value %q, expected none.", logLine, key, actual)
+		return false
+	}
+	return true
+}
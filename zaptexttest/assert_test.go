@@ -0,0 +1,62 @@
+package zaptexttest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingTB records the failures it's given instead of failing.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertFieldInLog(t *testing.T) {
+	const line = `info   db  query done  table="user \"accounts\""  note="two  spaces\tand tab"  rows=3  ok=true  ids=[1,2]`
+	tests := []struct {
+		desc     string
+		key      string
+		expected string
+		pass     bool
+	}{
+		{"quoted string", "table", `user "accounts"`, true},
+		{"quoted string with spaces and escapes", "note", "two  spaces\tand tab", true},
+		{"numeric", "rows", "3", true},
+		{"boolean", "ok", "true", true},
+		{"array", "ids", "[1,2]", true},
+		{"message", MessageKey, "query done", true},
+		{"level", LevelKey, "info", true},
+		{"wrong value", "rows", "4", false},
+		{"missing key", "cols", "3", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			rec := &recordingTB{TB: t}
+			assert.Equal(t, tt.pass, AssertFieldInLog(rec, line, tt.key, tt.expected), "Unexpected result.")
+			assert.Equal(t, tt.pass, len(rec.errors) == 0, "Unexpected failures: %v", rec.errors)
+		})
+	}
+
+	t.Run("unparsable", func(t *testing.T) {
+		rec := &recordingTB{TB: t}
+		assert.False(t, AssertFieldInLog(rec, `info  msg  k="unterminated`, "k", "x"), "Expected a failure.")
+		assert.Len(t, rec.errors, 1, "Expected a failure.")
+	})
+}
+
+func TestAssertFieldAbsent(t *testing.T) {
+	const line = `info  msg  rows=3`
+	rec := &recordingTB{TB: t}
+	assert.True(t, AssertFieldAbsent(rec, line, "cols"), "Expected an absent key to pass.")
+	assert.False(t, AssertFieldAbsent(rec, line, "rows"), "Expected a present key to fail.")
+	assert.Len(t, rec.errors, 1, "Expected one failure.")
+}
@@ -0,0 +1,98 @@
+// Package zaptexttest helps test code logging with zaptextencoder, the way
+// zaptest/observer does for structured entries.
+package zaptexttest
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/hms58/zaptextencoder"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// EncoderConfig is the configuration of the encoder used by NewTestLogger.
+// It has no time, so that entries are predictable, and its header has the
+// level, the logger name and the message.
+var EncoderConfig = zapcore.EncoderConfig{
+	LevelKey:       LevelKey,
+	NameKey:        NameKey,
+	MessageKey:     MessageKey,
+	StacktraceKey:  "stacktrace",
+	LineEnding:     zapcore.DefaultLineEnding,
+	EncodeLevel:    zapcore.LowercaseLevelEncoder,
+	EncodeDuration: zapcore.StringDurationEncoder,
+	EncodeName:     zapcore.FullNameEncoder,
+}
+
+// NewTestLogger returns a logger, enabled at every level, writing its
+// entries with a text encoder to the returned ObservedText. Options are
+// passed on to the encoder.
+func NewTestLogger(t testing.TB, opts ...zaptextencoder.Option) (*zap.Logger, *ObservedText) {
+	t.Helper()
+	observed := &ObservedText{}
+	core := zapcore.NewCore(zaptextencoder.NewTextEncoder(EncoderConfig, opts...), observed, zapcore.DebugLevel)
+	return zap.New(core), observed
+}
+
+// ObservedText collects the entries written by a logger of NewTestLogger.
+// It's safe for concurrent use.
+type ObservedText struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+var _ zapcore.WriteSyncer = (*ObservedText)(nil)
+
+// Write records an encoded entry. The trailing line ending is dropped,
+// while the lines of a stack trace are kept.
+func (o *ObservedText) Write(p []byte) (int, error) {
+	line := string(bytes.TrimRight(p, "\r\n"))
+	o.mu.Lock()
+	o.lines = append(o.lines, line)
+	o.mu.Unlock()
+	return len(p), nil
+}
+
+// Sync does nothing.
+func (o *ObservedText) Sync() error {
+	return nil
+}
+
+// Len returns the number of entries written so far.
+func (o *ObservedText) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.lines)
+}
+
+// Lines returns the entries written so far, one string each.
+func (o *ObservedText) Lines() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.lines...)
+}
+
+// Entries returns the entries written so far, parsed into maps of keys to
+// values. The level, the logger name and the message are stored under
+// LevelKey, NameKey and MessageKey. An entry which can't be parsed gives a
+// nil map.
+func (o *ObservedText) Entries() []map[string]string {
+	lines := o.Lines()
+	entries := make([]map[string]string, len(lines))
+	for i, line := range lines {
+		entries[i], _ = ParseTextLogLine(line)
+	}
+	return entries
+}
+
+// TakeAll returns the entries written so far, as Lines does, and forgets
+// them.
+func (o *ObservedText) TakeAll() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	lines := o.lines
+	o.lines = nil
+	return lines
+}
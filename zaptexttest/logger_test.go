@@ -0,0 +1,49 @@
+package zaptexttest
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/hms58/zaptextencoder"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestNewTestLogger(t *testing.T) {
+	logger, observed := NewTestLogger(t)
+	logger.Named("db").Info("query done", zap.String("table", "users"), zap.Int("rows", 3))
+	logger.With(zap.String("request_id", "abc")).Warn("slow request", zap.Error(errors.New("timeout")))
+
+	assert.Equal(t, []string{
+		`info   db  query done  table="users"  rows=3`,
+		`warn   request_id="abc"  slow request  error="timeout"`,
+	}, observed.Lines(), "Unexpected lines.")
+	assert.Equal(t, []map[string]string{
+		{LevelKey: "info", NameKey: "db", MessageKey: "query done", "table": "users", "rows": "3"},
+		{LevelKey: "warn", MessageKey: "slow request", "request_id": "abc", "error": "timeout"},
+	}, observed.Entries(), "Unexpected entries.")
+
+	assert.Equal(t, 2, len(observed.TakeAll()), "Unexpected number of entries taken.")
+	assert.Equal(t, 0, observed.Len(), "Expected TakeAll to forget the entries.")
+}
+
+func TestNewTestLoggerOptions(t *testing.T) {
+	logger, observed := NewTestLogger(t, zaptextencoder.WithSensitiveKeys([]string{"password"}))
+	logger.Info("login", zap.String("password", "hunter2"))
+	assert.Equal(t, []string{`info   login  password="[REDACTED]"`}, observed.Lines(), "Unexpected lines.")
+}
+
+func TestNewTestLoggerConcurrent(t *testing.T) {
+	logger, observed := NewTestLogger(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Info("hello")
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 10, observed.Len(), "Unexpected number of entries.")
+}
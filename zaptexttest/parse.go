@@ -0,0 +1,172 @@
+package zaptexttest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// The keys holding the header elements of a parsed line.
+const (
+	TimeKey    = "ts"
+	LevelKey   = "level"
+	NameKey    = "logger"
+	CallerKey  = "caller"
+	MessageKey = "msg"
+)
+
+const _separator = "  "
+
+// ParseTextLogLine parses the first line of an entry written by the text
+// encoder, with the default field separator and key delimiter, into a map
+// of keys to values. Escaped keys and quoted values are unescaped, while
+// other values, objects and arrays included, are kept as they are
+// written.
+//
+// The header elements have no keys. The last one, the message, is stored
+// under MessageKey, and the others under TimeKey, LevelKey, CallerKey or
+// NameKey depending on what they look like: a level, a number or a
+// timestamp, a file.go:line caller, or anything else. A message holding
+// the separator or looking like a key=value field can't be told apart, so
+// it's parsed wrongly.
+func ParseTextLogLine(line string) (map[string]string, error) {
+	if i := strings.IndexAny(line, "\r\n"); i >= 0 {
+		line = line[:i]
+	}
+	if strings.TrimSpace(line) == "" {
+		return nil, errors.New("empty line")
+	}
+
+	fields := make(map[string]string)
+	var header []string
+	for rest := line; rest != ""; {
+		rest = strings.TrimLeft(rest, " ")
+		if rest == "" {
+			break
+		}
+		key, value, n, ok, err := parseField(rest)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			n = strings.Index(rest, _separator)
+			if n < 0 {
+				n = len(rest)
+			}
+			header = append(header, rest[:n])
+		} else {
+			fields[key] = value
+		}
+		rest = rest[n:]
+		if rest != "" && !strings.HasPrefix(rest, _separator) {
+			return nil, fmt.Errorf("expected a separator before %q", rest)
+		}
+	}
+
+	if len(header) > 0 {
+		fields[MessageKey] = header[len(header)-1]
+		for _, elem := range header[:len(header)-1] {
+			fields[headerKey(elem)] = elem
+		}
+	}
+	return fields, nil
+}
+
+// parseField parses the key=value field s starts with, returning the
+// length of its text. It reports !ok if s doesn't start with a field.
+func parseField(s string) (key, value string, n int, ok bool, err error) {
+	eq := strings.IndexByte(s, '=')
+	if eq <= 0 || strings.IndexByte(s[:eq], ' ') >= 0 {
+		return "", "", 0, false, nil
+	}
+	key, rest := s[:eq], s[eq+1:]
+	n = eq + 1
+	if strings.IndexByte(key, '\\') >= 0 {
+		if err := json.Unmarshal([]byte(`"`+key+`"`), &key); err != nil {
+			return "", "", 0, false, fmt.Errorf("key %q: %v", s[:eq], err)
+		}
+	}
+	switch {
+	case strings.HasPrefix(rest, `"`):
+		end, err := quotedEnd(rest)
+		if err != nil {
+			return "", "", 0, false, fmt.Errorf("value of %q: %v", key, err)
+		}
+		if err := json.Unmarshal([]byte(rest[:end]), &value); err != nil {
+			return "", "", 0, false, fmt.Errorf("value of %q: %v", key, err)
+		}
+		return key, value, n + end, true, nil
+	case strings.HasPrefix(rest, "{"), strings.HasPrefix(rest, "["):
+		end, err := nestedEnd(rest)
+		if err != nil {
+			return "", "", 0, false, fmt.Errorf("value of %q: %v", key, err)
+		}
+		return key, rest[:end], n + end, true, nil
+	}
+	end := strings.Index(rest, _separator)
+	if end < 0 {
+		end = len(rest)
+	}
+	return key, rest[:end], n + end, true, nil
+}
+
+// quotedEnd returns the length of the quoted string s starts with.
+func quotedEnd(s string) (int, error) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		}
+	}
+	return 0, errors.New("unterminated quoted string")
+}
+
+// nestedEnd returns the length of the object or array s starts with.
+func nestedEnd(s string) (int, error) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			end, err := quotedEnd(s[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += end - 1
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i + 1, nil
+			}
+		}
+	}
+	return 0, errors.New("unterminated object or array")
+}
+
+// headerKey guesses which header element elem is.
+func headerKey(elem string) string {
+	var level zapcore.Level
+	if level.UnmarshalText([]byte(strings.TrimSpace(elem))) == nil {
+		return LevelKey
+	}
+	if _, err := strconv.ParseFloat(elem, 64); err == nil {
+		return TimeKey
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.000Z0700"} {
+		if _, err := time.Parse(layout, elem); err == nil {
+			return TimeKey
+		}
+	}
+	if i := strings.LastIndexByte(elem, ':'); i > 0 && strings.HasSuffix(elem[:i], ".go") {
+		return CallerKey
+	}
+	return NameKey
+}
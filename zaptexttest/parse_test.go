@@ -0,0 +1,114 @@
+package zaptexttest
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseTextLogLineRoundTrip(t *testing.T) {
+	// The field types of TestTextEncoderObjectFields.
+	tests := []struct {
+		desc     string
+		field    zapcore.Field
+		key      string
+		expected string
+	}{
+		{"binary", zap.Binary("k", []byte("ab12")), "k", "YWIxMg=="},
+		{"bool", zap.Bool("k", true), "k", "true"},
+		{"escaped key", zap.Bool(`k\`, false), `k\`, "false"},
+		{"byteString", zap.ByteString("k", []byte(`v\`)), "k", `v\`},
+		{"empty byteString", zap.ByteString("k", nil), "k", ""},
+		{"complex128", zap.Complex128("k", 1+2i), "k", "1+2i"},
+		{"complex64", zap.Complex64("k", 1+2i), "k", "1+2i"},
+		{"duration", zap.Duration("k", time.Second), "k", "1s"},
+		{"float64", zap.Float64("k", 1e10), "k", "10000000000"},
+		{"float64 NaN", zap.Float64("k", math.NaN()), "k", "NaN"},
+		{"float64 -Inf", zap.Float64("k", math.Inf(-1)), "k", "-Inf"},
+		{"float32", zap.Float32("k", 1.5), "k", "1.5"},
+		{"int", zap.Int("k", -42), "k", "-42"},
+		{"int64", zap.Int64("k", 42), "k", "42"},
+		{"int32", zap.Int32("k", 42), "k", "42"},
+		{"int16", zap.Int16("k", 42), "k", "42"},
+		{"int8", zap.Int8("k", 42), "k", "42"},
+		{"string", zap.String("k", `say "hi"`+"\n"), "k", `say "hi"` + "\n"},
+		{"empty string", zap.String("k", ""), "k", ""},
+		{"string with separator", zap.String("k", "a  b"), "k", "a  b"},
+		{"time", zap.Time("k", time.Unix(1, 0)), "k", "1000000000"},
+		{"uint", zap.Uint("k", 42), "k", "42"},
+		{"uint64", zap.Uint64("k", 42), "k", "42"},
+		{"uint32", zap.Uint32("k", 42), "k", "42"},
+		{"uint16", zap.Uint16("k", 42), "k", "42"},
+		{"uint8", zap.Uint8("k", 42), "k", "42"},
+		{"uintptr", zap.Uintptr("k", 42), "k", "42"},
+		{"reflect", zap.Reflect("k", map[string]interface{}{"a": "b c"}), "k", `{"a":"b c"}`},
+		{"strings", zap.Strings("k", []string{"a", "b  c"}), "k", `["a","b  c"]`},
+		{
+			desc: "object",
+			field: zap.Object("k", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+				enc.AddString("s", "} ]")
+				return nil
+			})),
+			key:      "k",
+			expected: `{s="} ]"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			logger, observed := NewTestLogger(t)
+			logger.Info("msg", tt.field, zap.Int("after", 1))
+			lines := observed.Lines()
+			require.Len(t, lines, 1, "Expected one entry.")
+
+			fields, err := ParseTextLogLine(lines[0])
+			require.NoError(t, err, "Can't parse %q.", lines[0])
+			assert.Equal(t, map[string]string{
+				LevelKey:   "info",
+				MessageKey: "msg",
+				tt.key:     tt.expected,
+				"after":    "1",
+			}, fields, "Unexpected fields of %q.", lines[0])
+		})
+	}
+}
+
+func TestParseTextLogLineHeader(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected map[string]string
+	}{
+		{
+			line:     "2021-01-02T03:04:05.000Z  INFO   db  main.go:42  done  k=1",
+			expected: map[string]string{TimeKey: "2021-01-02T03:04:05.000Z", LevelKey: "INFO", NameKey: "db", CallerKey: "main.go:42", MessageKey: "done", "k": "1"},
+		},
+		{
+			line:     "1609556645.5  warn  k=1  with context\n\tstack trace",
+			expected: map[string]string{TimeKey: "1609556645.5", LevelKey: "warn", MessageKey: "with context", "k": "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		fields, err := ParseTextLogLine(tt.line)
+		if assert.NoError(t, err, "Can't parse %q.", tt.line) {
+			assert.Equal(t, tt.expected, fields, "Unexpected fields of %q.", tt.line)
+		}
+	}
+}
+
+func TestParseTextLogLineErrors(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"info  msg  k=\"unterminated",
+		"info  msg  k={a=1",
+		"info  msg  k=\"a\"b",
+	} {
+		_, err := ParseTextLogLine(line)
+		assert.Error(t, err, "Expected an error parsing %q.", line)
+	}
+}